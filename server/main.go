@@ -2,11 +2,23 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
+	"x/pkg/auth"
+	"x/pkg/bookmark"
 	"x/pkg/controllers"
+	"x/pkg/follow"
+	"x/pkg/like"
+	"x/pkg/migrations"
+	"x/pkg/post"
 	"x/pkg/repository"
+	"x/pkg/search"
+	"x/pkg/timeline"
+	"x/pkg/trend"
 	"x/pkg/user"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -23,28 +35,220 @@ func main() {
 	}
 	defer conn.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(conn, os.Args[2:]); err != nil {
+			log.Printf("Unable to migrate database: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if os.Getenv("MIGRATE_ON_START") == "true" {
+		if err := migrate(conn, []string{"up"}); err != nil {
+			log.Printf("Unable to migrate database: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	queryTimeout := durationEnv("QUERY_TIMEOUT", 5*time.Second)
+
+	secret := os.Getenv("AUTH_SECRET")
+	if secret == "" {
+		log.Printf("AUTH_SECRET must be set\n")
+		os.Exit(1)
+	}
+
 	repo := repository.New(conn)
 
-	userService := user.New(repo)
+	authService := auth.New([]byte(secret), auth.DefaultTokenTTL)
 
-	controllers := controllers.New(userService)
+	userService := user.New(repo, durationEnv("USER_RETENTION", user.DefaultRetention))
+	timelineService := newTimelineService(repo)
+	postService := post.New(repo, timelineService)
+	followService := follow.New(repo, timelineService)
+	likeService := like.New(repo)
+	bookmarkService := bookmark.New(repo, postService)
+	searchService := search.New(repo, postService)
+
+	trendWindows := os.Getenv("TREND_WINDOWS")
+	if trendWindows == "" {
+		trendWindows = trend.DefaultWindows
+	}
+	windows, err := trend.ParseWindows(trendWindows)
+	if err != nil {
+		log.Printf("Invalid TREND_WINDOWS: %v\n", err)
+		os.Exit(1)
+	}
+	trendService := trend.New(repo, windows)
+
+	controllers := controllers.New(userService, postService, followService, likeService, bookmarkService, searchService, trendService, authService)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /api/v1/users", controllers.GetAllUsers)
-	mux.HandleFunc("GET /api/v1/users/{email}", controllers.GetUser)
+	mux.HandleFunc("GET /api/v1/users/by-handle/{handle}", controllers.GetUserByHandle)
+	mux.HandleFunc("GET /api/v1/handles/{handle}/availability", controllers.CheckHandle)
 	mux.HandleFunc("POST /api/v1/users", controllers.CreateUser)
-	mux.HandleFunc("PUT /api/v1/users", controllers.UpdateUser)
+	mux.HandleFunc("PATCH /api/v1/users/{id}", auth.RequireUser(controllers.UpdateUser))
+	mux.HandleFunc("DELETE /api/v1/users/{id}", auth.RequireUser(controllers.DeleteUser))
+	mux.HandleFunc("POST /api/v1/users/{id}/follow", auth.RequireUser(controllers.Follow))
+	mux.HandleFunc("DELETE /api/v1/users/{id}/follow", auth.RequireUser(controllers.Unfollow))
+	mux.HandleFunc("GET /api/v1/users/{id}/followers", controllers.GetFollowers)
+	mux.HandleFunc("GET /api/v1/users/{id}/following", controllers.GetFollowing)
+	mux.HandleFunc("POST /api/v1/auth/login", controllers.Login)
+	mux.HandleFunc("POST /api/v1/auth/logout", controllers.Logout)
+	mux.HandleFunc("POST /api/v1/posts", auth.RequireUser(controllers.CreatePost))
+	mux.HandleFunc("GET /api/v1/posts/{id}", controllers.GetPost)
+	mux.HandleFunc("GET /api/v1/posts/{id}/thread", controllers.GetThread)
+	mux.HandleFunc("DELETE /api/v1/posts/{id}", auth.RequireUser(controllers.DeletePost))
+	mux.HandleFunc("POST /api/v1/posts/{id}/repost", auth.RequireUser(controllers.Repost))
+	mux.HandleFunc("DELETE /api/v1/posts/{id}/repost", auth.RequireUser(controllers.Unrepost))
+	mux.HandleFunc("POST /api/v1/posts/{id}/like", auth.RequireUser(controllers.Like))
+	mux.HandleFunc("DELETE /api/v1/posts/{id}/like", auth.RequireUser(controllers.Unlike))
+	mux.HandleFunc("POST /api/v1/posts/{id}/bookmark", auth.RequireUser(controllers.Bookmark))
+	mux.HandleFunc("DELETE /api/v1/posts/{id}/bookmark", auth.RequireUser(controllers.Unbookmark))
+	mux.HandleFunc("GET /api/v1/bookmarks", auth.RequireUser(controllers.GetBookmarks))
+	mux.HandleFunc("GET /api/v1/search", controllers.Search)
+	mux.HandleFunc("GET /api/v1/trends", controllers.GetTrends)
+	mux.HandleFunc("GET /api/v1/timeline", auth.RequireUser(controllers.GetTimeline))
 	
+	go purgeDeletedUsers(userService, durationEnv("USER_PURGE_INTERVAL", time.Hour), queryTimeout)
+	go refreshTrends(trendService, durationEnv("TREND_REFRESH_INTERVAL", time.Minute), queryTimeout)
+
 	log.Println("Server started on port 3000")
 
 	handler := cors.New(cors.Options{
 		AllowedOrigins: []string{"http://localhost:5173"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete},
+		AllowedHeaders: []string{"Authorization", "Content-Type", "If-Match"},
+		ExposedHeaders: []string{"ETag"},
 		AllowCredentials: true,
 		Debug: true,
-	}).Handler(mux)
+	}).Handler(withTimeout(queryTimeout, auth.Middleware(authService, userService, mux)))
 	
 	if err := http.ListenAndServe(":3000", handler); err != nil {
 		log.Printf("Unable to start server: %v\n", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
+
+// durationEnv reads a duration such as "30s" from the environment variable
+// name, or returns fallback when it is not set.
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	return duration
+}
+
+// purgeDeletedUsers hard-deletes the accounts past their retention period
+// every interval, for as long as the server runs.
+func purgeDeletedUsers(userService user.Service, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		purged, err := userService.PurgeDeletedUsers(ctx)
+		cancel()
+
+		if err != nil {
+			log.Printf("Unable to purge deleted users: %v\n", err)
+			continue
+		}
+
+		if purged > 0 {
+			log.Printf("Purged %d deleted users\n", purged)
+		}
+	}
+}
+
+// refreshTrends computes the trends served right away and then every interval,
+// for as long as the server runs.
+func refreshTrends(trendService trend.Service, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := trendService.Refresh(ctx)
+		cancel()
+
+		if err != nil {
+			log.Printf("Unable to refresh trends: %v\n", err)
+		}
+
+		<-ticker.C
+	}
+}
+
+// withTimeout gives every request a deadline, bounding the database queries
+// made with the request context while serving it.
+func withTimeout(timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// migrate runs the migrate subcommand: "up" applies every pending migration
+// and "down [steps]" reverts the newest ones, one step by default.
+func migrate(conn *pgxpool.Pool, args []string) error {
+	migrator, err := migrations.New(conn)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		args = []string{"up"}
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(context.Background())
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		return migrator.Down(context.Background(), steps)
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up or down", args[0])
+	}
+}
+
+// newTimelineService builds the fan-out-on-write timeline selected by the
+// TIMELINE_STORE environment variable ("postgres" or "memory"). It returns nil
+// when none is selected, in which case timelines are computed on read.
+func newTimelineService(repo repository.Repository) timeline.Service {
+	threshold := timeline.DefaultFanOutThreshold
+	if value := os.Getenv("TIMELINE_FANOUT_THRESHOLD"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			log.Printf("Invalid TIMELINE_FANOUT_THRESHOLD: %v\n", err)
+			os.Exit(1)
+		}
+		threshold = parsed
+	}
+
+	switch os.Getenv("TIMELINE_STORE") {
+	case "postgres":
+		return timeline.New(repo, timeline.NewPostgresStore(repo), threshold)
+	case "memory":
+		return timeline.New(repo, timeline.NewMemoryStore(), threshold)
+	default:
+		return nil
+	}
+}
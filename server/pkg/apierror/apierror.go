@@ -0,0 +1,85 @@
+package apierror
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// Codes are stable, machine-readable identifiers for API errors. Clients should
+// branch on these rather than on messages.
+const (
+	CodeBadRequest           = "BAD_REQUEST"
+	CodeInternal             = "INTERNAL_ERROR"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeInvalidToken         = "INVALID_TOKEN"
+	CodeTokenExpired         = "TOKEN_EXPIRED"
+	CodeAccountDeactivated   = "ACCOUNT_DEACTIVATED"
+	CodeInvalidCredentials   = "INVALID_CREDENTIALS"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeConflict             = "CONFLICT"
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+	CodePreconditionFailed   = "PRECONDITION_FAILED"
+	CodeValidation           = "VALIDATION_FAILED"
+	CodeInvalidDate          = "INVALID_DATE"
+	CodeInvalidCursor        = "INVALID_CURSOR"
+	CodeInvalidSort          = "INVALID_SORT"
+	CodeUserNotFound         = "USER_NOT_FOUND"
+	CodeEmailTaken           = "EMAIL_TAKEN"
+	CodeHandleTaken          = "HANDLE_TAKEN"
+	CodePostEmpty            = "POST_EMPTY"
+	CodePostTooLong          = "POST_TOO_LONG"
+	CodePostNotFound         = "POST_NOT_FOUND"
+	CodeAlreadyReposted      = "ALREADY_REPOSTED"
+	CodeNotReposted          = "NOT_REPOSTED"
+	CodeSelfFollow           = "SELF_FOLLOW"
+	CodeAlreadyFollowing     = "ALREADY_FOLLOWING"
+	CodeNotFollowing         = "NOT_FOLLOWING"
+	CodeQueryEmpty           = "QUERY_EMPTY"
+	CodeQueryTooLong         = "QUERY_TOO_LONG"
+	CodeInvalidSearchType    = "INVALID_SEARCH_TYPE"
+	CodeInvalidWindow        = "INVALID_WINDOW"
+)
+
+// Error is the body of every failed API response.
+type Error struct {
+	Status int `json:"-"`
+	Code string `json:"code"`
+	Message string `json:"message"`
+	Details any `json:"details,omitempty"`
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{
+		Status: status,
+		Code: code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of e carrying extra information for the client,
+// such as which fields failed validation.
+func (e *Error) WithDetails(details any) *Error {
+	withDetails := *e
+	withDetails.Details = details
+	return &withDetails
+}
+
+// Write renders err as a JSON response with err.Status as the status code.
+func Write(w http.ResponseWriter, err *Error) {
+	jsonBytes, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		log.Printf("error marshalling api error: %+v", marshalErr)
+		jsonBytes = []byte(`{"code":"` + CodeInternal + `","message":"internal server error"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(err.Status)
+	w.Write(jsonBytes)
+}
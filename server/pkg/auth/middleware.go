@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"x/pkg/apierror"
+)
+
+// CookieName is the cookie the token is stored in for browser clients.
+const CookieName = "x_session"
+
+type contextKey struct{}
+
+// ErrInactiveUser is why a valid token is rejected once the user it was issued
+// to deleted their account.
+var ErrInactiveUser = errors.New("account is deactivated")
+
+// Users tells whether the user a token was issued to can still use it.
+type Users interface {
+	IsActive(ctx context.Context, userID int) (bool, error)
+}
+
+// tokenErrorKey stores why the token sent with a request was rejected.
+type tokenErrorKey struct{}
+
+// WithUserID returns a copy of ctx carrying the authenticated user's id.
+func WithUserID(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+// UserID returns the authenticated user's id stored in ctx by Middleware.
+func UserID(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(contextKey{}).(int)
+	return userID, ok
+}
+
+// Middleware reads a token from the Authorization header ("Bearer <token>") or
+// the session cookie and, when it is valid and its user is still active, stores
+// the user's id in the request context. Requests without a valid token pass
+// through anonymously, so that a stale cookie the client cannot remove does not
+// keep it from logging in or out. RequireUser tells why the token was rejected.
+func Middleware(s Service, users Users, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := tokenFromRequest(r)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, err := s.ParseToken(token)
+		if err != nil {
+			log.Printf("rejected token: %+v", err)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenErrorKey{}, err)))
+			return
+		}
+
+		active, err := users.IsActive(r.Context(), userID)
+		if err != nil {
+			log.Printf("error checking user: %+v", err)
+			apierror.Write(w, apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "internal server error"))
+			return
+		}
+
+		if !active {
+			log.Printf("rejected token of inactive user %d", userID)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenErrorKey{}, ErrInactiveUser)))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), userID)))
+	})
+}
+
+// RequireUser rejects requests that Middleware did not authenticate, with the
+// reason the token was rejected when one was sent.
+func RequireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := UserID(r.Context()); !ok {
+			apierror.Write(w, unauthorized(r.Context()))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func unauthorized(ctx context.Context) *apierror.Error {
+	err, _ := ctx.Value(tokenErrorKey{}).(error)
+	switch {
+	case err == nil:
+		return apierror.New(http.StatusUnauthorized, apierror.CodeUnauthorized, "authentication required")
+	case errors.Is(err, ErrInactiveUser):
+		return apierror.New(http.StatusUnauthorized, apierror.CodeAccountDeactivated, err.Error())
+	case errors.Is(err, ErrExpiredToken):
+		return apierror.New(http.StatusUnauthorized, apierror.CodeTokenExpired, err.Error())
+	default:
+		return apierror.New(http.StatusUnauthorized, apierror.CodeInvalidToken, err.Error())
+	}
+}
+
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found {
+			return header
+		}
+		return strings.TrimSpace(token)
+	}
+
+	if cookie, err := r.Cookie(CookieName); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
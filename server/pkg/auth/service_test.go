@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"x/pkg/apierror"
+)
+
+// stubUsers reports every user active but those in it.
+type stubUsers map[int]bool
+
+func (s stubUsers) IsActive(ctx context.Context, userID int) (bool, error) {
+	return !s[userID], nil
+}
+
+func TestIssueToken_RoundTrips(t *testing.T) {
+	service := New([]byte("secret"), time.Hour)
+
+	token, expiresAt, err := service.IssueToken(42)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if !expiresAt.After(time.Now()) {
+		t.Errorf("expected expiry in the future, actual: %+v", expiresAt)
+	}
+
+	actual, err := service.ParseToken(token)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if actual != 42 {
+		t.Errorf("expected: %+v, actual: %+v", 42, actual)
+	}
+}
+
+func TestParseToken_WrongSecret_ReturnsError(t *testing.T) {
+	token, _, _ := New([]byte("secret"), time.Hour).IssueToken(42)
+
+	_, actual := New([]byte("other secret"), time.Hour).ParseToken(token)
+	if !errors.Is(actual, ErrInvalidToken) {
+		t.Errorf("expected: %+v, actual: %+v", ErrInvalidToken, actual)
+	}
+}
+
+func TestParseToken_TamperedPayload_ReturnsError(t *testing.T) {
+	service := New([]byte("secret"), time.Hour)
+
+	token, _, _ := service.IssueToken(42)
+	other, _, _ := service.IssueToken(1)
+
+	parts := strings.Split(token, ".")
+	parts[1] = strings.Split(other, ".")[1]
+
+	_, actual := service.ParseToken(strings.Join(parts, "."))
+	if !errors.Is(actual, ErrInvalidToken) {
+		t.Errorf("expected: %+v, actual: %+v", ErrInvalidToken, actual)
+	}
+}
+
+func TestParseToken_Expired_ReturnsError(t *testing.T) {
+	service := &service{secret: []byte("secret"), ttl: time.Hour, now: time.Now}
+
+	token, _, _ := service.IssueToken(42)
+
+	service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	_, actual := service.ParseToken(token)
+	if !errors.Is(actual, ErrExpiredToken) {
+		t.Errorf("expected: %+v, actual: %+v", ErrExpiredToken, actual)
+	}
+}
+
+func TestMiddleware_InjectsUserID(t *testing.T) {
+	service := New([]byte("secret"), time.Hour)
+	token, _, _ := service.IssueToken(42)
+
+	for _, setToken := range []func(r *http.Request){
+		func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) },
+		func(r *http.Request) { r.AddCookie(&http.Cookie{Name: CookieName, Value: token}) },
+	} {
+		var actual int
+		handler := Middleware(service, stubUsers{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actual, _ = UserID(r.Context())
+		}))
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		setToken(r)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		if actual != 42 {
+			t.Errorf("expected: %+v, actual: %+v", 42, actual)
+		}
+	}
+}
+
+func TestMiddleware_InvalidToken_PassesAnonymously(t *testing.T) {
+	service := New([]byte("secret"), time.Hour)
+
+	called := false
+	handler := Middleware(service, stubUsers{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if _, ok := UserID(r.Context()); ok {
+			t.Errorf("expected no user id")
+		}
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+	r.AddCookie(&http.Cookie{Name: CookieName, Value: "not.a.token"})
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if !called {
+		t.Errorf("expected handler to be called")
+	}
+}
+
+func TestRequireUser_RejectedToken_ReturnsReason(t *testing.T) {
+	expired := &service{secret: []byte("secret"), ttl: time.Hour, now: func() time.Time { return time.Now().Add(-2 * time.Hour) }}
+	expiredToken, _, _ := expired.IssueToken(42)
+
+	service := New([]byte("secret"), time.Hour)
+
+	cases := map[string]string{
+		"not.a.token": apierror.CodeInvalidToken,
+		expiredToken:  apierror.CodeTokenExpired,
+	}
+
+	for token, expected := range cases {
+		handler := Middleware(service, stubUsers{}, RequireUser(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("expected handler not to be called")
+		}))
+
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		var body apierror.Error
+		json.NewDecoder(w.Body).Decode(&body)
+		if w.Code != http.StatusUnauthorized || body.Code != expected {
+			t.Errorf("expected: %+v %+v, actual: %+v %+v", http.StatusUnauthorized, expected, w.Code, body.Code)
+		}
+	}
+}
+
+func TestRequireUser_Anonymous_ReturnsUnauthorized(t *testing.T) {
+	handler := RequireUser(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected handler not to be called")
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected: %+v, actual: %+v", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestRequireUser_InactiveUser_ReturnsUnauthorized(t *testing.T) {
+	service := New([]byte("secret"), time.Hour)
+	token, _, _ := service.IssueToken(42)
+
+	handler := Middleware(service, stubUsers{42: true}, RequireUser(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected handler not to be called")
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	var body apierror.Error
+	json.NewDecoder(w.Body).Decode(&body)
+	if w.Code != http.StatusUnauthorized || body.Code != apierror.CodeAccountDeactivated {
+		t.Errorf("expected: %+v %+v, actual: %+v %+v", http.StatusUnauthorized, apierror.CodeAccountDeactivated, w.Code, body.Code)
+	}
+}
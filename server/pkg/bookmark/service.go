@@ -0,0 +1,85 @@
+package bookmark
+
+import (
+	"context"
+	"errors"
+	"log"
+	"x/pkg/model"
+	"x/pkg/post"
+	"x/pkg/repository"
+)
+
+var ErrPostNotFound = errors.New("post to bookmark not found")
+
+// Service saves posts for later. Bookmarks are private: every method acts on
+// the bookmarks of the user it is given, who must be the one authenticated.
+type Service interface {
+	Bookmark(ctx context.Context, userID, postID int) error
+	Unbookmark(ctx context.Context, userID, postID int) error
+	GetBookmarks(ctx context.Context, userID int, cursor string, limit int) (*model.Page[model.BookmarkedPost], error)
+}
+
+type service struct {
+	db repository.BookmarkRepository
+	posts post.Service
+}
+
+// New returns a bookmark Service. posts adds likes and reposted posts to the
+// bookmarked posts it returns.
+func New(db repository.BookmarkRepository, posts post.Service) Service {
+	return &service{
+		db: db,
+		posts: posts,
+	}
+}
+
+func (s *service) Bookmark(ctx context.Context, userID, postID int) error {
+	if err := s.db.Bookmark(ctx, userID, postID); err != nil {
+		log.Printf("error bookmarking post: %+v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) Unbookmark(ctx context.Context, userID, postID int) error {
+	if err := s.db.Unbookmark(ctx, userID, postID); err != nil {
+		log.Printf("error removing bookmark: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) GetBookmarks(ctx context.Context, userID int, cursor string, limit int) (*model.Page[model.BookmarkedPost], error) {
+	after, err := model.ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = model.PageLimit(limit)
+
+	page, err := model.FetchPage(limit, func(limit int) ([]model.BookmarkedPost, error) {
+		return s.db.GetBookmarks(ctx, userID, after, limit)
+	}, func(last model.BookmarkedPost) string {
+		return model.Cursor{CreatedAt: last.BookmarkedAt, ID: last.ID}.String()
+	})
+	if err != nil {
+		log.Printf("error fetching bookmarks: %+v", err)
+		return nil, err
+	}
+
+	posts := make([]*model.Post, len(page.Items))
+	for i := range page.Items {
+		posts[i] = &page.Items[i].Post
+	}
+
+	if err := s.posts.Decorate(ctx, userID, posts); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,124 @@
+package bookmark
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/post"
+	"x/pkg/repository"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRepo struct {
+	mock.Mock
+}
+
+func (m *mockRepo) Bookmark(ctx context.Context, userID, postID int) error {
+	args := m.Called(userID, postID)
+
+	return args.Error(0)
+}
+
+func (m *mockRepo) Unbookmark(ctx context.Context, userID, postID int) error {
+	args := m.Called(userID, postID)
+
+	return args.Error(0)
+}
+
+func (m *mockRepo) GetBookmarks(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.BookmarkedPost, error) {
+	args := m.Called(userID, after, limit)
+
+	return args.Get(0).([]model.BookmarkedPost), args.Error(1)
+}
+
+// mockPosts only implements Decorate, the rest of post.Service is left nil.
+type mockPosts struct {
+	post.Service
+	mock.Mock
+}
+
+func (m *mockPosts) Decorate(ctx context.Context, viewerID int, posts []*model.Post) error {
+	args := m.Called(viewerID, posts)
+
+	return args.Error(0)
+}
+
+func TestBookmark_NoPost_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, &mockPosts{})
+
+	mockRepo.On("Bookmark", 1, 2).Return(repository.ErrNotFound)
+
+	actual := service.Bookmark(context.Background(), 1, 2)
+	if !errors.Is(actual, ErrPostNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrPostNotFound, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUnbookmark_ReturnsNoError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, &mockPosts{})
+
+	mockRepo.On("Unbookmark", 1, 2).Return(nil)
+
+	actual := service.Unbookmark(context.Background(), 1, 2)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetBookmarks_ReturnsPageWithNextCursor(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockPosts := &mockPosts{}
+	service := New(mockRepo, mockPosts)
+
+	dummyTime := time.Now().UTC().Truncate(time.Microsecond)
+	bookmarks := []model.BookmarkedPost{
+		{Post: model.Post{ID: 7, AuthorID: 2, CreatedAt: dummyTime.Add(-time.Hour)}, BookmarkedAt: dummyTime},
+		{Post: model.Post{ID: 3, AuthorID: 2, CreatedAt: dummyTime.Add(-2 * time.Hour)}, BookmarkedAt: dummyTime.Add(-time.Minute)},
+		{Post: model.Post{ID: 9, AuthorID: 2, CreatedAt: dummyTime}, BookmarkedAt: dummyTime.Add(-2 * time.Minute)},
+	}
+
+	mockRepo.On("GetBookmarks", 1, (*model.Cursor)(nil), 3).Return(bookmarks, nil)
+	mockPosts.On("Decorate", 1, mock.AnythingOfType("[]*model.Post")).Return(nil)
+
+	actual, err := service.GetBookmarks(context.Background(), 1, "", 2)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if len(actual.Items) != 2 {
+		t.Fatalf("expected 2 bookmarks, actual: %+v", actual.Items)
+	}
+
+	next, err := model.ParseCursor(actual.NextCursor)
+	if err != nil {
+		t.Fatalf("expected a valid next cursor, actual: %+v", err)
+	}
+
+	if next.ID != 3 || !next.CreatedAt.Equal(bookmarks[1].BookmarkedAt) {
+		t.Errorf("expected cursor at the bookmark of post 3, actual: %+v", next)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockPosts.AssertExpectations(t)
+}
+
+func TestGetBookmarks_InvalidCursor_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, &mockPosts{})
+
+	_, actual := service.GetBookmarks(context.Background(), 1, "not a cursor", 10)
+	if !errors.Is(actual, model.ErrInvalidCursor) {
+		t.Errorf("expected: %+v, actual: %+v", model.ErrInvalidCursor, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetBookmarks", mock.Anything, mock.Anything, mock.Anything)
+}
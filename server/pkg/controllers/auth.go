@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"x/pkg/auth"
+	"x/pkg/model"
+)
+
+type AuthController interface {
+	Login(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+}
+
+func (u *controller) Login(w http.ResponseWriter, r *http.Request) {
+	var loginRequest model.Login
+
+	err := json.NewDecoder(r.Body).Decode(&loginRequest)
+	if err != nil {
+		log.Printf("error decoding body: %+v", err)
+		writeError(w, errBadRequest)
+		return
+	}
+
+	loggedInUser, err := u.userService.Login(r.Context(), loginRequest.Email, loginRequest.Password)
+	if err != nil {
+		log.Printf("error logging in: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	token, expiresAt, err := u.authService.IssueToken(loggedInUser.ID)
+	if err != nil {
+		log.Printf("error issuing token: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(model.Session{Token: token, ExpiresAt: expiresAt, User: loggedInUser})
+	if err != nil {
+		log.Printf("error marshalling session: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name: auth.CookieName,
+		Value: token,
+		Path: "/",
+		Expires: expiresAt,
+		HttpOnly: true,
+		Secure: r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
+
+func (u *controller) Logout(w http.ResponseWriter, r *http.Request) {
+	clearSessionCookie(w, r)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name: auth.CookieName,
+		Value: "",
+		Path: "/",
+		MaxAge: -1,
+		HttpOnly: true,
+		Secure: r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"x/pkg/auth"
+)
+
+type BookmarkController interface {
+	Bookmark(w http.ResponseWriter, r *http.Request)
+	Unbookmark(w http.ResponseWriter, r *http.Request)
+	GetBookmarks(w http.ResponseWriter, r *http.Request)
+}
+
+func (u *controller) Bookmark(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	userID, _ := auth.UserID(r.Context())
+
+	err = u.bookmarkService.Bookmark(r.Context(), userID, postID)
+	if err != nil {
+		log.Printf("error bookmarking post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *controller) Unbookmark(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	userID, _ := auth.UserID(r.Context())
+
+	err = u.bookmarkService.Unbookmark(r.Context(), userID, postID)
+	if err != nil {
+		log.Printf("error removing bookmark: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBookmarks lists the bookmarks of the authenticated user. There is no way
+// to read anyone else's.
+func (u *controller) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	userID, _ := auth.UserID(r.Context())
+
+	var err error
+	limit := 0
+	if query.Has("limit") {
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			log.Printf("bad limit: %+v", query.Get("limit"))
+			writeError(w, errBadRequest)
+			return
+		}
+	}
+
+	bookmarks, err := u.bookmarkService.GetBookmarks(r.Context(), userID, query.Get("cursor"), limit)
+	if err != nil {
+		log.Printf("error fetching bookmarks: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(bookmarks)
+	if err != nil {
+		log.Printf("error marshalling bookmarks: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
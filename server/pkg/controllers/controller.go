@@ -1,19 +1,47 @@
 package controllers
 
 import (
+	"x/pkg/auth"
+	"x/pkg/bookmark"
+	"x/pkg/follow"
+	"x/pkg/like"
+	"x/pkg/post"
+	"x/pkg/search"
+	"x/pkg/trend"
 	"x/pkg/user"
 )
 
 type Controller interface {
 	UserController
+	PostController
+	FollowController
+	LikeController
+	BookmarkController
+	SearchController
+	TrendController
+	AuthController
 }
 
 type controller struct {
 	userService user.Service
+	postService post.Service
+	followService follow.Service
+	likeService like.Service
+	bookmarkService bookmark.Service
+	searchService search.Service
+	trendService trend.Service
+	authService auth.Service
 }
 
-func New(userService user.Service) Controller {
+func New(userService user.Service, postService post.Service, followService follow.Service, likeService like.Service, bookmarkService bookmark.Service, searchService search.Service, trendService trend.Service, authService auth.Service) Controller {
 	return &controller{
 		userService: userService,
+		postService: postService,
+		followService: followService,
+		likeService: likeService,
+		bookmarkService: bookmarkService,
+		searchService: searchService,
+		trendService: trendService,
+		authService: authService,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"x/pkg/apierror"
+	"x/pkg/bookmark"
+	"x/pkg/follow"
+	"x/pkg/like"
+	"x/pkg/model"
+	"x/pkg/post"
+	"x/pkg/repository"
+	"x/pkg/search"
+	"x/pkg/trend"
+	"x/pkg/user"
+	"x/pkg/validate"
+)
+
+var (
+	errBadRequest           = apierror.New(http.StatusBadRequest, apierror.CodeBadRequest, "bad request")
+	errCannotUpdateOthers   = apierror.New(http.StatusForbidden, apierror.CodeForbidden, "users can only update themselves")
+	errCannotDeleteOthers   = apierror.New(http.StatusForbidden, apierror.CodeForbidden, "users can only delete themselves")
+	errPreconditionRequired = apierror.New(http.StatusPreconditionRequired, apierror.CodePreconditionRequired, "updates must send the user's ETag in If-Match")
+	errInternal             = apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "internal server error")
+	errNotFound             = apierror.New(http.StatusNotFound, apierror.CodeNotFound, "resource not found")
+	errConflict             = apierror.New(http.StatusConflict, apierror.CodeConflict, "resource already exists")
+	errInvalidSearchType    = apierror.New(http.StatusBadRequest, apierror.CodeInvalidSearchType, "type should be users or posts")
+	errValidation           = apierror.New(http.StatusBadRequest, apierror.CodeValidation, "request validation failed")
+)
+
+type errorMapping struct {
+	err error
+	status int
+	code string
+}
+
+// serviceErrors maps the sentinel errors returned by services to the status
+// and code they are reported with. The error's own message is sent along. The
+// first match wins.
+var serviceErrors = []errorMapping{
+	{model.ErrInvalidDate, http.StatusBadRequest, apierror.CodeInvalidDate},
+	{model.ErrInvalidCursor, http.StatusBadRequest, apierror.CodeInvalidCursor},
+	{model.ErrInvalidSort, http.StatusBadRequest, apierror.CodeInvalidSort},
+	{user.ErrInvalidCredentials, http.StatusUnauthorized, apierror.CodeInvalidCredentials},
+	{post.ErrEmptyPost, http.StatusBadRequest, apierror.CodePostEmpty},
+	{post.ErrPostTooLong, http.StatusBadRequest, apierror.CodePostTooLong},
+	{post.ErrPostNotFound, http.StatusNotFound, apierror.CodePostNotFound},
+	{post.ErrParentNotFound, http.StatusNotFound, apierror.CodePostNotFound},
+	{post.ErrQuotedNotFound, http.StatusNotFound, apierror.CodePostNotFound},
+	{post.ErrAlreadyReposted, http.StatusConflict, apierror.CodeAlreadyReposted},
+	{post.ErrNotReposted, http.StatusNotFound, apierror.CodeNotReposted},
+	{post.ErrNotAuthor, http.StatusForbidden, apierror.CodeForbidden},
+	{post.ErrAuthorNotFound, http.StatusUnauthorized, apierror.CodeUnauthorized},
+	{follow.ErrSelfFollow, http.StatusBadRequest, apierror.CodeSelfFollow},
+	{follow.ErrAlreadyFollowing, http.StatusConflict, apierror.CodeAlreadyFollowing},
+	{follow.ErrNotFollowing, http.StatusNotFound, apierror.CodeNotFollowing},
+	{follow.ErrUserNotFound, http.StatusNotFound, apierror.CodeUserNotFound},
+	{like.ErrPostNotFound, http.StatusNotFound, apierror.CodePostNotFound},
+	{bookmark.ErrPostNotFound, http.StatusNotFound, apierror.CodePostNotFound},
+	{search.ErrEmptyQuery, http.StatusBadRequest, apierror.CodeQueryEmpty},
+	{search.ErrQueryTooLong, http.StatusBadRequest, apierror.CodeQueryTooLong},
+	{trend.ErrUnknownWindow, http.StatusBadRequest, apierror.CodeInvalidWindow},
+	{user.ErrUserNotFound, http.StatusNotFound, apierror.CodeUserNotFound},
+	{user.ErrEmailTaken, http.StatusConflict, apierror.CodeEmailTaken},
+	{user.ErrHandleTaken, http.StatusConflict, apierror.CodeHandleTaken},
+	{user.ErrVersionMismatch, http.StatusPreconditionFailed, apierror.CodePreconditionFailed},
+	{model.ErrInvalidETag, http.StatusPreconditionFailed, apierror.CodePreconditionFailed},
+}
+
+// repositoryErrors are the generic repository errors a service let through.
+// They can wrap raw database errors, naming tables and constraints, so they are
+// reported with a fixed message instead of their own.
+var repositoryErrors = []struct {
+	err error
+	apiErr *apierror.Error
+}{
+	{repository.ErrNotFound, errNotFound},
+	{repository.ErrConflict, errConflict},
+}
+
+// writeError is how every handler reports a failure. API errors are written as
+// they are, validation errors are reported with the failing fields, known
+// service errors are translated through serviceErrors, then repositoryErrors,
+// and anything else is logged and hidden behind a generic internal error.
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		apierror.Write(w, apiErr)
+		return
+	}
+
+	var validationErrs validate.Errors
+	if errors.As(err, &validationErrs) {
+		apierror.Write(w, errValidation.WithDetails(map[string]validate.Errors{"fields": validationErrs}))
+		return
+	}
+
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			apierror.Write(w, apierror.New(mapping.status, mapping.code, err.Error()))
+			return
+		}
+	}
+
+	for _, mapping := range repositoryErrors {
+		if errors.Is(err, mapping.err) {
+			apierror.Write(w, mapping.apiErr)
+			return
+		}
+	}
+
+	log.Printf("unexpected error: %+v", err)
+	apierror.Write(w, errInternal)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"x/pkg/apierror"
+	"x/pkg/follow"
+	"x/pkg/post"
+	"x/pkg/repository"
+	"x/pkg/user"
+	"x/pkg/validate"
+)
+
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) apierror.Error {
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected: %+v, actual: %+v", "application/json", contentType)
+	}
+
+	var body apierror.Error
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("an error '%s' was not expected when decoding the error body", err)
+	}
+
+	return body
+}
+
+func TestWriteError_APIError_WritesItAsIs(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	writeError(w, errBadRequest.WithDetails(map[string]string{"limit": "not a number"}))
+
+	body := decodeError(t, w)
+	if w.Code != http.StatusBadRequest || body.Code != apierror.CodeBadRequest {
+		t.Errorf("expected: %+v %+v, actual: %+v %+v", http.StatusBadRequest, apierror.CodeBadRequest, w.Code, body.Code)
+	}
+
+	if body.Details == nil {
+		t.Errorf("expected details to be written")
+	}
+}
+
+func TestWriteError_ServiceError_IsMapped(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	writeError(w, fmt.Errorf("following: %w", follow.ErrAlreadyFollowing))
+
+	body := decodeError(t, w)
+	if w.Code != http.StatusConflict || body.Code != apierror.CodeAlreadyFollowing {
+		t.Errorf("expected: %+v %+v, actual: %+v %+v", http.StatusConflict, apierror.CodeAlreadyFollowing, w.Code, body.Code)
+	}
+}
+
+func TestWriteError_UnknownError_IsHidden(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	writeError(w, errors.New("connection refused"))
+
+	body := decodeError(t, w)
+	if w.Code != http.StatusInternalServerError || body.Code != apierror.CodeInternal {
+		t.Errorf("expected: %+v %+v, actual: %+v %+v", http.StatusInternalServerError, apierror.CodeInternal, w.Code, body.Code)
+	}
+
+	if body.Message == "connection refused" {
+		t.Errorf("expected internal error message not to leak")
+	}
+}
+
+func TestWriteError_RepositoryErrors_FallBackToGenericCodes(t *testing.T) {
+	cases := []struct {
+		err error
+		status int
+		code string
+	}{
+		{repository.ErrNotFound, http.StatusNotFound, apierror.CodeNotFound},
+		{&repository.ConflictError{Constraint: "some_key", Err: errors.New("duplicate")}, http.StatusConflict, apierror.CodeConflict},
+		{user.ErrEmailTaken, http.StatusConflict, apierror.CodeEmailTaken},
+		{post.ErrAlreadyReposted, http.StatusConflict, apierror.CodeAlreadyReposted},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+
+		writeError(w, c.err)
+
+		body := decodeError(t, w)
+		if w.Code != c.status || body.Code != c.code {
+			t.Errorf("expected: %+v %+v, actual: %+v %+v", c.status, c.code, w.Code, body.Code)
+		}
+	}
+}
+
+func TestWriteError_RepositoryErrors_HideDatabaseMessages(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	writeError(w, &repository.ConflictError{Constraint: "users_email_key", Err: errors.New("duplicate key value violates unique constraint")})
+
+	body := decodeError(t, w)
+	if strings.Contains(body.Message, "users_email_key") || strings.Contains(body.Message, "duplicate key") {
+		t.Errorf("expected database error not to leak, actual: %+v", body.Message)
+	}
+}
+
+func TestWriteError_ValidationErrors_ListFields(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	writeError(w, validate.Errors{"email": {"should be a valid email address"}})
+
+	body := decodeError(t, w)
+	if w.Code != http.StatusBadRequest || body.Code != apierror.CodeValidation {
+		t.Errorf("expected: %+v %+v, actual: %+v %+v", http.StatusBadRequest, apierror.CodeValidation, w.Code, body.Code)
+	}
+
+	details, _ := body.Details.(map[string]any)
+	fields, _ := details["fields"].(map[string]any)
+	if _, ok := fields["email"]; !ok {
+		t.Errorf("expected email in details, actual: %+v", body.Details)
+	}
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"x/pkg/auth"
+)
+
+type FollowController interface {
+	Follow(w http.ResponseWriter, r *http.Request)
+	Unfollow(w http.ResponseWriter, r *http.Request)
+	GetFollowers(w http.ResponseWriter, r *http.Request)
+	GetFollowing(w http.ResponseWriter, r *http.Request)
+}
+
+func (u *controller) Follow(w http.ResponseWriter, r *http.Request) {
+	followeeID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad user id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	followerID, _ := auth.UserID(r.Context())
+
+	err = u.followService.Follow(r.Context(), followerID, followeeID)
+	if err != nil {
+		log.Printf("error following user: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (u *controller) Unfollow(w http.ResponseWriter, r *http.Request) {
+	followeeID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad user id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	followerID, _ := auth.UserID(r.Context())
+
+	err = u.followService.Unfollow(r.Context(), followerID, followeeID)
+	if err != nil {
+		log.Printf("error unfollowing user: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *controller) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad user id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	users, err := u.followService.GetFollowers(r.Context(), id)
+	if err != nil {
+		log.Printf("error fetching followers: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	hideEmails(r, users)
+
+	jsonBytes, err := json.Marshal(users)
+	if err != nil {
+		log.Printf("error marshalling users: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
+
+func (u *controller) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad user id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	users, err := u.followService.GetFollowing(r.Context(), id)
+	if err != nil {
+		log.Printf("error fetching following: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	hideEmails(r, users)
+
+	jsonBytes, err := json.Marshal(users)
+	if err != nil {
+		log.Printf("error marshalling users: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"x/pkg/auth"
+)
+
+type LikeController interface {
+	Like(w http.ResponseWriter, r *http.Request)
+	Unlike(w http.ResponseWriter, r *http.Request)
+}
+
+func (u *controller) Like(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	userID, _ := auth.UserID(r.Context())
+
+	err = u.likeService.Like(r.Context(), userID, postID)
+	if err != nil {
+		log.Printf("error liking post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *controller) Unlike(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	userID, _ := auth.UserID(r.Context())
+
+	err = u.likeService.Unlike(r.Context(), userID, postID)
+	if err != nil {
+		log.Printf("error unliking post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
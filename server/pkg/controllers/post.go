@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"x/pkg/auth"
+	"x/pkg/model"
+)
+
+type PostController interface {
+	CreatePost(w http.ResponseWriter, r *http.Request)
+	GetPost(w http.ResponseWriter, r *http.Request)
+	GetThread(w http.ResponseWriter, r *http.Request)
+	DeletePost(w http.ResponseWriter, r *http.Request)
+	Repost(w http.ResponseWriter, r *http.Request)
+	Unrepost(w http.ResponseWriter, r *http.Request)
+	GetTimeline(w http.ResponseWriter, r *http.Request)
+}
+
+func (u *controller) CreatePost(w http.ResponseWriter, r *http.Request) {
+	var createPostRequest model.CreatePost
+
+	err := json.NewDecoder(r.Body).Decode(&createPostRequest)
+	if err != nil {
+		log.Printf("error decoding body: %+v", err)
+		writeError(w, errBadRequest)
+		return
+	}
+
+	authorID, _ := auth.UserID(r.Context())
+
+	createdPost, err := u.postService.CreatePost(r.Context(), authorID, createPostRequest.Text, createPostRequest.ParentID, createPostRequest.QuoteOfID)
+	if err != nil {
+		log.Printf("error creating post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(createdPost)
+	if err != nil {
+		log.Printf("error marshalling post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(jsonBytes)
+}
+
+func (u *controller) GetPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	viewerID, _ := auth.UserID(r.Context())
+
+	post, err := u.postService.GetPost(r.Context(), id, viewerID)
+	if err != nil {
+		log.Printf("error fetching post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(post)
+	if err != nil {
+		log.Printf("error marshalling post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
+
+func (u *controller) GetThread(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 0
+	if query.Has("limit") {
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			log.Printf("bad limit: %+v", query.Get("limit"))
+			writeError(w, errBadRequest)
+			return
+		}
+	}
+
+	viewerID, _ := auth.UserID(r.Context())
+
+	thread, err := u.postService.GetThread(r.Context(), id, viewerID, query.Get("cursor"), limit)
+	if err != nil {
+		log.Printf("error fetching thread: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(thread)
+	if err != nil {
+		log.Printf("error marshalling thread: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
+
+func (u *controller) DeletePost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	userID, _ := auth.UserID(r.Context())
+
+	err = u.postService.DeletePost(r.Context(), id, userID)
+	if err != nil {
+		log.Printf("error deleting post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *controller) Repost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	userID, _ := auth.UserID(r.Context())
+
+	repost, err := u.postService.Repost(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error reposting post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(repost)
+	if err != nil {
+		log.Printf("error marshalling post: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(jsonBytes)
+}
+
+func (u *controller) Unrepost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad post id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	userID, _ := auth.UserID(r.Context())
+
+	err = u.postService.Unrepost(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error deleting repost: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (u *controller) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	userID, _ := auth.UserID(r.Context())
+
+	var err error
+	limit := 0
+	if query.Has("limit") {
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			log.Printf("bad limit: %+v", query.Get("limit"))
+			writeError(w, errBadRequest)
+			return
+		}
+	}
+
+	timeline, err := u.postService.GetTimeline(r.Context(), userID, query.Get("cursor"), limit)
+	if err != nil {
+		log.Printf("error fetching timeline: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(timeline)
+	if err != nil {
+		log.Printf("error marshalling timeline: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
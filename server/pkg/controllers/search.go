@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"x/pkg/auth"
+	"x/pkg/model"
+)
+
+type SearchController interface {
+	Search(w http.ResponseWriter, r *http.Request)
+}
+
+// Search finds users or posts, as chosen by the type parameter, matching the q
+// parameter. Posts are searched when no type is given.
+func (u *controller) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var err error
+	limit := 0
+	if query.Has("limit") {
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			log.Printf("bad limit: %+v", query.Get("limit"))
+			writeError(w, errBadRequest)
+			return
+		}
+	}
+
+	var results any
+	switch query.Get("type") {
+	case model.SearchUsers:
+		var users *model.Page[model.User]
+		users, err = u.searchService.SearchUsers(r.Context(), query.Get("q"), query.Get("cursor"), limit)
+		if users != nil {
+			hideEmails(r, users.Items)
+		}
+		results = users
+	case model.SearchPosts, "":
+		viewerID, _ := auth.UserID(r.Context())
+		results, err = u.searchService.SearchPosts(r.Context(), viewerID, query.Get("q"), query.Get("cursor"), limit)
+	default:
+		log.Printf("bad search type: %+v", query.Get("type"))
+		writeError(w, errInvalidSearchType)
+		return
+	}
+	if err != nil {
+		log.Printf("error searching: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(results)
+	if err != nil {
+		log.Printf("error marshalling search results: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
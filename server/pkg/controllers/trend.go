@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+type TrendController interface {
+	GetTrends(w http.ResponseWriter, r *http.Request)
+}
+
+// GetTrends returns the trending hashtags over the window named by the window
+// parameter, such as "24h". They are served from memory, as last computed by
+// the background refresh.
+func (u *controller) GetTrends(w http.ResponseWriter, r *http.Request) {
+	trends, err := u.trendService.GetTrends(r.URL.Query().Get("window"))
+	if err != nil {
+		log.Printf("error fetching trends: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(trends)
+	if err != nil {
+		log.Printf("error marshalling trends: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
@@ -2,35 +2,54 @@ package controllers
 
 import (
 	"encoding/json"
-	"errors"
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
+	"x/pkg/auth"
 	"x/pkg/model"
 )
 
-var badDobError = errors.New("Format for date of birth should be DD-MM-YYYY")
-
 type UserController interface {
 	GetAllUsers(w http.ResponseWriter, r *http.Request)
-	GetUser(w http.ResponseWriter, r *http.Request)
+	GetUserByHandle(w http.ResponseWriter, r *http.Request)
+	CheckHandle(w http.ResponseWriter, r *http.Request)
 	CreateUser(w http.ResponseWriter, r *http.Request)
 	UpdateUser(w http.ResponseWriter, r *http.Request)
+	DeleteUser(w http.ResponseWriter, r *http.Request)
 }
 
 func (u *controller) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := u.userService.GetAllUsers()
+	query := r.URL.Query()
+
+	params := model.ListUsers{
+		NamePrefix: query.Get("name"),
+		Sort: query.Get("sort"),
+		Cursor: query.Get("cursor"),
+	}
+
+	if query.Has("limit") {
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			log.Printf("bad limit: %+v", query.Get("limit"))
+			writeError(w, errBadRequest)
+			return
+		}
+		params.Limit = limit
+	}
+
+	users, err := u.userService.GetAllUsers(r.Context(), params)
 	if err != nil {
 		log.Printf("error fetching users: %+v", err)
-		http.Error(w, "error fetching users", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
+	hideEmails(r, users.Items)
+
 	jsonBytes, err := json.Marshal(users)
 	if err != nil {
 		log.Printf("error marshalling users: %+v", err)
-		http.Error(w, "error fetching users", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -38,26 +57,43 @@ func (u *controller) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonBytes)
 }
 
-func (u *controller) GetUser(w http.ResponseWriter, r *http.Request) {
-	email := r.PathValue("email")
-	
-	user, err := u.userService.GetUserByEmail(email)
+func (u *controller) GetUserByHandle(w http.ResponseWriter, r *http.Request) {
+	handle := r.PathValue("handle")
+
+	user, err := u.userService.GetUserByHandle(r.Context(), handle)
 	if err != nil {
-		log.Printf("error fetching users: %+v", err)
-		http.Error(w, "error fetching users", http.StatusInternalServerError)
+		log.Printf("error fetching user: %+v", err)
+		writeError(w, err)
 		return
 	}
 
-	if user == nil {
-		log.Printf("no user found");
-		http.Error(w, "no user found", http.StatusNoContent)
+	hideEmail(r, user)
+
+	jsonBytes, err := json.Marshal(user)
+	if err != nil {
+		log.Printf("error marshalling user: %+v", err)
+		writeError(w, err)
 		return
 	}
 
-	jsonBytes, err := json.Marshal(user)
+	w.Header().Set("ETag", user.ETag())
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
+
+// CheckHandle tells whether a handle is free to register, and if not, why.
+func (u *controller) CheckHandle(w http.ResponseWriter, r *http.Request) {
+	availability, err := u.userService.CheckHandle(r.Context(), r.PathValue("handle"))
 	if err != nil {
-		log.Printf("error marshalling users: %+v", err)
-		http.Error(w, "error fetching users", http.StatusInternalServerError)
+		log.Printf("error checking handle: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(availability)
+	if err != nil {
+		log.Printf("error marshalling handle availability: %+v", err)
+		writeError(w, err)
 		return
 	}
 
@@ -71,74 +107,130 @@ func (u *controller) CreateUser(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&createUserRequest)
 	if err != nil {
 		log.Printf("error decoding body: %+v", err)
-		http.Error(w, "bad request", http.StatusBadRequest)
+		writeError(w, errBadRequest)
 		return
 	}
 
-	if err := validatedDob(createUserRequest.DOB); errors.Is(err, badDobError) {
-		log.Printf("bad format for dob: %+v", createUserRequest.DOB)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := createUserRequest.Validate(); err != nil {
+		log.Printf("invalid request: %+v", err)
+		writeError(w, err)
 		return
 	}
 
-	if err := u.userService.CreateUser(createUserRequest.Name, createUserRequest.Email, createUserRequest.Bio, createUserRequest.DOB); err != nil {
+	err = u.userService.CreateUser(r.Context(), createUserRequest.Name, createUserRequest.Handle, createUserRequest.Email, createUserRequest.Bio, createUserRequest.DOB, createUserRequest.Password)
+	if err != nil {
 		log.Printf("error creating user: %+v", err)
-		http.Error(w, "error creating user", http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 }
 
+// UpdateUser applies a JSON merge patch (RFC 7396) to the user's profile and
+// responds with the updated user. The request must carry the ETag the client
+// last saw in If-Match, so that concurrent edits are not silently lost.
 func (u *controller) UpdateUser(w http.ResponseWriter, r *http.Request) {
-	var updateUserRequest model.UpdateUser
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad user id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
+	}
+
+	currentUserID, _ := auth.UserID(r.Context())
+	if id != currentUserID {
+		log.Printf("user %d tried to update user %d", currentUserID, id)
+		writeError(w, errCannotUpdateOthers)
+		return
+	}
 
-	err := json.NewDecoder(r.Body).Decode(&updateUserRequest)
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		writeError(w, errPreconditionRequired)
+		return
+	}
+
+	version, err := model.ParseETag(ifMatch)
+	if err != nil {
+		log.Printf("bad If-Match: %+v", ifMatch)
+		writeError(w, err)
+		return
+	}
+
+	var patch model.UpdateUser
+
+	err = json.NewDecoder(r.Body).Decode(&patch)
 	if err != nil {
 		log.Printf("error decoding body: %+v", err)
-		http.Error(w, "bad request", http.StatusBadRequest)
+		writeError(w, errBadRequest)
 		return
 	}
 
-	if err := validatedDob(updateUserRequest.DOB); errors.Is(err, badDobError) {
-		log.Printf("bad format for dob: %+v", updateUserRequest.DOB)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := patch.Validate(); err != nil {
+		log.Printf("invalid request: %+v", err)
+		writeError(w, err)
 		return
 	}
 
-	if err := u.userService.UpdateUser(updateUserRequest.ID, updateUserRequest.Name, updateUserRequest.Email, updateUserRequest.Bio, updateUserRequest.DOB); err != nil {
-		log.Printf("error creating user: %+v", err)
-		http.Error(w, "error creating user", http.StatusInternalServerError)
+	user, err := u.userService.UpdateUser(r.Context(), id, version, patch)
+	if err != nil {
+		log.Printf("error updating user: %+v", err)
+		writeError(w, err)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(user)
+	if err != nil {
+		log.Printf("error marshalling user: %+v", err)
+		writeError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", user.ETag())
 	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
 }
 
-func validatedDob(dob string) error {
-	if dob == "" {
-		return nil
+// DeleteUser deactivates the current user and logs them out. Logging in again
+// within the retention period reactivates the account.
+func (u *controller) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("bad user id: %+v", r.PathValue("id"))
+		writeError(w, errBadRequest)
+		return
 	}
-	
-	parsedDob := strings.Split(dob, "-")
-	if len(parsedDob) != 3 || (parsedDob[0] == "" || parsedDob[1] == "" || parsedDob[2] == "") {
-		return badDobError
+
+	currentUserID, _ := auth.UserID(r.Context())
+	if id != currentUserID {
+		log.Printf("user %d tried to delete user %d", currentUserID, id)
+		writeError(w, errCannotDeleteOthers)
+		return
 	}
 
-	_, err := strconv.Atoi(parsedDob[0])
-	if err != nil {
-		return badDobError
+	if err := u.userService.DeleteUser(r.Context(), id); err != nil {
+		log.Printf("error deleting user: %+v", err)
+		writeError(w, err)
+		return
 	}
 
-	_, err = strconv.Atoi(parsedDob[1])
-	if err != nil {
-		return badDobError
+	clearSessionCookie(w, r)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// hideEmail blanks the email address of user unless they are the one making
+// the request, as emails are private.
+func hideEmail(r *http.Request, user *model.User) {
+	currentUserID, _ := auth.UserID(r.Context())
+	if user.ID != currentUserID {
+		user.Email = ""
 	}
+}
 
-	_, err = strconv.Atoi(parsedDob[2])
-	if err != nil {
-		return badDobError
+func hideEmails(r *http.Request, users []model.User) {
+	for i := range users {
+		hideEmail(r, &users[i])
 	}
-	
-	return nil
-}
\ No newline at end of file
+}
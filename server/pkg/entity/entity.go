@@ -0,0 +1,124 @@
+// Package entity finds the hashtags and mentions in the text of a post.
+package entity
+
+import (
+	"strings"
+	"unicode"
+	"x/pkg/model"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Extract returns the hashtags and mentions in text, in the order they appear.
+//
+// A hashtag is a # followed by letters, digits, marks and underscores, at least
+// one of them a letter, in any script. A mention is an @ followed by a handle.
+// Either must start the text or follow a character that cannot be part of a
+// word, so that the @ of an email address and the # of a URL fragment are not
+// taken for one. Words that look like URLs are skipped entirely, as are
+// handles directly followed by another @, as in "@name@example.com". Mentions
+// are returned without a UserID.
+func Extract(text string) []model.Entity {
+	runes := []rune(text)
+	entities := []model.Entity{}
+
+	for i := 0; i < len(runes); i++ {
+		if startsWord(runes, i) && isURL(word(runes, i)) {
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			continue
+		}
+
+		if (runes[i] != '#' && runes[i] != '@') || !canStart(runes, i) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		body := runes[i+1 : end]
+		if runes[i] == '#' && isHashtag(body) {
+			entities = append(entities, model.Entity{
+				Type: model.EntityHashtag,
+				Start: i,
+				End: end,
+				Text: norm.NFC.String(strings.ToLower(string(body))),
+			})
+		}
+		if runes[i] == '@' && isHandle(body) && (end == len(runes) || runes[end] != '@') {
+			entities = append(entities, model.Entity{
+				Type: model.EntityMention,
+				Start: i,
+				End: end,
+				Text: strings.ToLower(string(body)),
+			})
+		}
+
+		i = end - 1
+	}
+
+	return entities
+}
+
+func startsWord(runes []rune, i int) bool {
+	return !unicode.IsSpace(runes[i]) && (i == 0 || unicode.IsSpace(runes[i-1]))
+}
+
+// word returns the run of non-space characters starting at i.
+func word(runes []rune, i int) string {
+	end := i
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+
+	return string(runes[i:end])
+}
+
+func isURL(word string) bool {
+	lower := strings.ToLower(word)
+	return strings.Contains(lower, "://") || strings.HasPrefix(lower, "www.")
+}
+
+// canStart reports whether a hashtag or mention may start at i, that is when
+// the character before it cannot be part of a word, a path or another entity.
+func canStart(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	prev := runes[i-1]
+	return !isWordRune(prev) && !strings.ContainsRune("#@/&", prev)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+func isHashtag(body []rune) bool {
+	for _, r := range body {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isHandle reports whether body has the shape of a handle. Whether the handle
+// is taken is only known once mentions are resolved.
+func isHandle(body []rune) bool {
+	if len(body) < model.MinHandleLength || len(body) > model.MaxHandleLength {
+		return false
+	}
+
+	for _, r := range body {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+
+	return true
+}
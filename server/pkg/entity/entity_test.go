@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"testing"
+	"x/pkg/model"
+	"x/pkg/util"
+)
+
+func TestExtract(t *testing.T) {
+	cases := []struct {
+		text string
+		expected []model.Entity
+	}{
+		{"hello", []model.Entity{}},
+		{"#Go is fun", []model.Entity{{Type: model.EntityHashtag, Start: 0, End: 3, Text: "go"}}},
+		{"hi @Dwight_S!", []model.Entity{{Type: model.EntityMention, Start: 3, End: 12, Text: "dwight_s"}}},
+		{"ça #Café, #日本語", []model.Entity{
+			{Type: model.EntityHashtag, Start: 3, End: 8, Text: "café"},
+			{Type: model.EntityHashtag, Start: 10, End: 14, Text: "日本語"},
+		}},
+		{"#café", []model.Entity{{Type: model.EntityHashtag, Start: 0, End: 6, Text: "café"}}},
+		{"mail michael@dundermifflin.com", []model.Entity{}},
+		{"see https://example.com/#top and www.example.com/@dwight", []model.Entity{}},
+		{"example.com/#top", []model.Entity{}},
+		{"#1 and #2020s", []model.Entity{{Type: model.EntityHashtag, Start: 7, End: 13, Text: "2020s"}}},
+		{"@ab @abcdefghijklmnop @jim@example.com", []model.Entity{}},
+		{"(@jim) #a#b", []model.Entity{
+			{Type: model.EntityMention, Start: 1, End: 5, Text: "jim"},
+			{Type: model.EntityHashtag, Start: 7, End: 9, Text: "a"},
+		}},
+	}
+
+	for _, c := range cases {
+		actual := Extract(c.text)
+		util.AssertJSON(actual, c.expected, t)
+	}
+}
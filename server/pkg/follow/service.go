@@ -0,0 +1,107 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"log"
+	"x/pkg/model"
+	"x/pkg/repository"
+	"x/pkg/timeline"
+)
+
+var (
+	ErrSelfFollow       = errors.New("users cannot follow themselves")
+	ErrAlreadyFollowing = errors.New("user is already being followed")
+	ErrNotFollowing     = errors.New("user is not being followed")
+	ErrUserNotFound     = errors.New("user to follow not found")
+)
+
+type Service interface {
+	Follow(ctx context.Context, followerID, followeeID int) error
+	Unfollow(ctx context.Context, followerID, followeeID int) error
+	GetFollowers(ctx context.Context, id int) ([]model.User, error)
+	GetFollowing(ctx context.Context, id int) ([]model.User, error)
+}
+
+type service struct {
+	db repository.FollowRepository
+	timelines timeline.Service
+}
+
+// New returns a follow Service. When timelines is not nil, the timeline of the
+// follower is updated with the posts of the account followed or unfollowed.
+func New(db repository.FollowRepository, timelines timeline.Service) Service {
+	return &service{
+		db: db,
+		timelines: timelines,
+	}
+}
+
+func (s *service) Follow(ctx context.Context, followerID, followeeID int) error {
+	if followerID == followeeID {
+		return ErrSelfFollow
+	}
+
+	created, err := s.db.Follow(ctx, followerID, followeeID)
+	if err != nil {
+		log.Printf("error following user: %+v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if !created {
+		return ErrAlreadyFollowing
+	}
+
+	if s.timelines != nil {
+		// the follow is already saved, so a failure to backfill the timeline is
+		// logged rather than failing the request
+		if err := s.timelines.Follow(context.WithoutCancel(ctx), followerID, followeeID); err != nil {
+			log.Printf("error backfilling timeline: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) Unfollow(ctx context.Context, followerID, followeeID int) error {
+	removed, err := s.db.Unfollow(ctx, followerID, followeeID)
+	if err != nil {
+		log.Printf("error unfollowing user: %+v", err)
+		return err
+	}
+
+	if !removed {
+		return ErrNotFollowing
+	}
+
+	if s.timelines != nil {
+		if err := s.timelines.Unfollow(context.WithoutCancel(ctx), followerID, followeeID); err != nil {
+			log.Printf("error removing posts from timeline: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) GetFollowers(ctx context.Context, id int) ([]model.User, error) {
+	users, err := s.db.GetFollowers(ctx, id)
+	if err != nil {
+		log.Printf("error fetching followers: %+v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (s *service) GetFollowing(ctx context.Context, id int) ([]model.User, error) {
+	users, err := s.db.GetFollowing(ctx, id)
+	if err != nil {
+		log.Printf("error fetching following: %+v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
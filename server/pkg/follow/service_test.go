@@ -0,0 +1,203 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"x/pkg/model"
+	"x/pkg/timeline"
+	"x/pkg/util"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRepo struct {
+	mock.Mock
+}
+
+func (m *mockRepo) Follow(ctx context.Context, followerID, followeeID int) (bool, error) {
+	args := m.Called(followerID, followeeID)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockRepo) Unfollow(ctx context.Context, followerID, followeeID int) (bool, error) {
+	args := m.Called(followerID, followeeID)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockRepo) GetFollowers(ctx context.Context, id int) ([]model.User, error) {
+	args := m.Called(id)
+
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (m *mockRepo) GetFollowing(ctx context.Context, id int) ([]model.User, error) {
+	args := m.Called(id)
+
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (m *mockRepo) GetFollowerIDs(ctx context.Context, id int) ([]int, error) {
+	args := m.Called(id)
+
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *mockRepo) CountFollowers(ctx context.Context, id int) (int, error) {
+	args := m.Called(id)
+
+	return args.Int(0), args.Error(1)
+}
+
+// mockTimelines only implements Follow and Unfollow, the rest of
+// timeline.Service is left nil.
+type mockTimelines struct {
+	timeline.Service
+	mock.Mock
+}
+
+func (m *mockTimelines) Follow(ctx context.Context, followerID, followeeID int) error {
+	args := m.Called(followerID, followeeID)
+
+	return args.Error(0)
+}
+
+func (m *mockTimelines) Unfollow(ctx context.Context, followerID, followeeID int) error {
+	args := m.Called(followerID, followeeID)
+
+	return args.Error(0)
+}
+
+func TestFollow_ReturnsNoError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	mockRepo.On("Follow", 1, 2).Return(true, nil)
+
+	actual := service.Follow(context.Background(), 1, 2)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFollow_Self_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	actual := service.Follow(context.Background(), 1, 1)
+	if !errors.Is(actual, ErrSelfFollow) {
+		t.Errorf("expected: %+v, actual: %+v", ErrSelfFollow, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "Follow", mock.Anything, mock.Anything)
+}
+
+func TestFollow_Duplicate_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	mockRepo.On("Follow", 1, 2).Return(false, nil)
+
+	actual := service.Follow(context.Background(), 1, 2)
+	if !errors.Is(actual, ErrAlreadyFollowing) {
+		t.Errorf("expected: %+v, actual: %+v", ErrAlreadyFollowing, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFollow_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	expected := errors.New("test error")
+
+	mockRepo.On("Follow", 1, 2).Return(false, expected)
+
+	actual := service.Follow(context.Background(), 1, 2)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFollow_WithTimelines_BackfillsTimeline(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockTimelines := &mockTimelines{}
+	service := New(mockRepo, mockTimelines)
+
+	mockRepo.On("Follow", 1, 2).Return(true, nil)
+	mockTimelines.On("Follow", 1, 2).Return(errors.New("test error"))
+
+	actual := service.Follow(context.Background(), 1, 2)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockTimelines.AssertExpectations(t)
+}
+
+func TestUnfollow_WithTimelines_RemovesPosts(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockTimelines := &mockTimelines{}
+	service := New(mockRepo, mockTimelines)
+
+	mockRepo.On("Unfollow", 1, 2).Return(true, nil)
+	mockTimelines.On("Unfollow", 1, 2).Return(nil)
+
+	actual := service.Unfollow(context.Background(), 1, 2)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockTimelines.AssertExpectations(t)
+}
+
+func TestUnfollow_NotFollowing_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	mockRepo.On("Unfollow", 1, 2).Return(false, nil)
+
+	actual := service.Unfollow(context.Background(), 1, 2)
+	if !errors.Is(actual, ErrNotFollowing) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFollowing, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetFollowers_ReturnsUsers(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	expected := []model.User{{ID: 1, Name: "user 1"}}
+
+	mockRepo.On("GetFollowers", 2).Return(expected, nil)
+
+	actual, _ := service.GetFollowers(context.Background(), 2)
+	util.AssertJSON(actual, expected, t)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetFollowing_ReturnsUsers(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	expected := []model.User{{ID: 2, Name: "user 2"}}
+
+	mockRepo.On("GetFollowing", 1).Return(expected, nil)
+
+	actual, _ := service.GetFollowing(context.Background(), 1)
+	util.AssertJSON(actual, expected, t)
+
+	mockRepo.AssertExpectations(t)
+}
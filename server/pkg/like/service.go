@@ -0,0 +1,48 @@
+package like
+
+import (
+	"context"
+	"errors"
+	"log"
+	"x/pkg/repository"
+)
+
+var ErrPostNotFound = errors.New("post to like not found")
+
+// Service likes and unlikes posts. Both are idempotent: liking a post already
+// liked or unliking one that is not leaves things as they are.
+type Service interface {
+	Like(ctx context.Context, userID, postID int) error
+	Unlike(ctx context.Context, userID, postID int) error
+}
+
+type service struct {
+	db repository.LikeRepository
+}
+
+func New(db repository.LikeRepository) Service {
+	return &service{
+		db: db,
+	}
+}
+
+func (s *service) Like(ctx context.Context, userID, postID int) error {
+	if err := s.db.Like(ctx, userID, postID); err != nil {
+		log.Printf("error liking post: %+v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) Unlike(ctx context.Context, userID, postID int) error {
+	if err := s.db.Unlike(ctx, userID, postID); err != nil {
+		log.Printf("error unliking post: %+v", err)
+		return err
+	}
+
+	return nil
+}
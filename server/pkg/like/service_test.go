@@ -0,0 +1,69 @@
+package like
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"x/pkg/repository"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRepo struct {
+	mock.Mock
+}
+
+func (m *mockRepo) Like(ctx context.Context, userID, postID int) error {
+	args := m.Called(userID, postID)
+
+	return args.Error(0)
+}
+
+func (m *mockRepo) Unlike(ctx context.Context, userID, postID int) error {
+	args := m.Called(userID, postID)
+
+	return args.Error(0)
+}
+
+func TestLike_ReturnsNoError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo)
+
+	mockRepo.On("Like", 1, 2).Return(nil)
+
+	actual := service.Like(context.Background(), 1, 2)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLike_NoPost_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo)
+
+	mockRepo.On("Like", 1, 2).Return(repository.ErrNotFound)
+
+	actual := service.Like(context.Background(), 1, 2)
+	if !errors.Is(actual, ErrPostNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrPostNotFound, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUnlike_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo)
+
+	expected := errors.New("test error")
+	mockRepo.On("Unlike", 1, 2).Return(expected)
+
+	actual := service.Unlike(context.Background(), 1, 2)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
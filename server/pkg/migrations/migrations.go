@@ -0,0 +1,212 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey identifies the advisory lock held while migrating, so that several
+// instances starting at once apply each migration exactly once.
+const lockKey = 7_330_417_201
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var ErrUnknownVersion = errors.New("database has a migration applied that is not known to this build")
+
+type Migration struct {
+	Version int
+	Name string
+	Up string
+	Down string
+}
+
+type dbConn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type Migrator interface {
+	// Up applies every pending migration in version order.
+	Up(ctx context.Context) error
+	// Down reverts the last steps applied migrations, newest first.
+	Down(ctx context.Context, steps int) error
+}
+
+type migrator struct {
+	db dbConn
+	migrations []Migration
+}
+
+// New returns a Migrator for the SQL files embedded in this package.
+func New(db dbConn) (Migrator, error) {
+	migrations, err := Load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrator{
+		db: db,
+		migrations: migrations,
+	}, nil
+}
+
+// Load reads migrations named <version>_<name>.(up|down).sql from the sql
+// directory of fsys. Every version must have both an up and a down file.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		contents, err := fs.ReadFile(fsys, "sql/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(tx pgx.Tx, applied []int) error {
+		done := map[int]bool{}
+		for _, version := range applied {
+			done[version] = true
+		}
+
+		for _, migration := range m.migrations {
+			if done[migration.Version] {
+				continue
+			}
+
+			log.Printf("applying migration %d_%s", migration.Version, migration.Name)
+			if _, err := tx.Exec(ctx, migration.Up); err != nil {
+				return fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			if _, err := tx.Exec(ctx, "insert into schema_migrations (version, name) values ($1, $2)", migration.Version, migration.Name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (m *migrator) Down(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(tx pgx.Tx, applied []int) error {
+		known := map[int]Migration{}
+		for _, migration := range m.migrations {
+			known[migration.Version] = migration
+		}
+
+		for i := len(applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+			migration, ok := known[applied[i]]
+			if !ok {
+				return fmt.Errorf("%w: %d", ErrUnknownVersion, applied[i])
+			}
+
+			log.Printf("reverting migration %d_%s", migration.Version, migration.Name)
+			if _, err := tx.Exec(ctx, migration.Down); err != nil {
+				return fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			if _, err := tx.Exec(ctx, "delete from schema_migrations where version = $1", migration.Version); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// locked runs fn in a single transaction holding the migration advisory lock,
+// passing it the versions already applied in ascending order. Postgres DDL is
+// transactional, so a failing migration leaves the schema untouched.
+func (m *migrator) locked(ctx context.Context, fn func(tx pgx.Tx, applied []int) error) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		log.Printf("error starting migration transaction: %+v", err)
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "select pg_advisory_xact_lock($1)", lockKey); err != nil {
+		log.Printf("error acquiring migration lock: %+v", err)
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `create table if not exists schema_migrations (
+		version integer primary key,
+		name text not null,
+		applied_at timestamptz not null default now()
+	)`); err != nil {
+		log.Printf("error creating schema_migrations: %+v", err)
+		return err
+	}
+
+	rows, err := tx.Query(ctx, "select version from schema_migrations order by version")
+	if err != nil {
+		log.Printf("error querying schema_migrations: %+v", err)
+		return err
+	}
+
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return err
+	}
+
+	if err := fn(tx, applied); err != nil {
+		log.Printf("error migrating: %+v", err)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,174 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "create_users", Up: "create table users ()", Down: "drop table users"},
+	{Version: 2, Name: "create_posts", Up: "create table posts ()", Down: "drop table posts"},
+}
+
+func expectLocked(mockDb pgxmock.PgxPoolIface, applied ...int) {
+	mockDb.ExpectBegin()
+	mockDb.ExpectExec("select pg_advisory_xact_lock").WithArgs(lockKey).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mockDb.ExpectExec("create table if not exists schema_migrations").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+
+	rows := mockDb.NewRows([]string{"version"})
+	for _, version := range applied {
+		rows.AddRow(version)
+	}
+	mockDb.ExpectQuery("select version from schema_migrations").WillReturnRows(rows)
+}
+
+func TestLoad_ReturnsEmbeddedMigrationsInOrder(t *testing.T) {
+	// act
+	migrations, err := Load(files)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// assert
+	if len(migrations) == 0 {
+		t.Fatalf("expected embedded migrations")
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("expected version %d, actual: %d", i+1, migration.Version)
+		}
+	}
+}
+
+func TestLoad_ReturnsErrorWhenDownIsMissing(t *testing.T) {
+	// arrange
+	fsys := fstest.MapFS{
+		"sql/0001_create_users.up.sql": {Data: []byte("create table users ()")},
+	}
+
+	// act
+	_, err := Load(fsys)
+
+	// assert
+	if err == nil {
+		t.Errorf("expected an error for a migration without a down file")
+	}
+}
+
+// upserted_at is the user's ETag, so follows, which update follower_count
+// through a trigger, must not bump it. That includes the backfill, which has to
+// run after users_set_upserted_at learns to skip count changes.
+func TestLoad_FollowerCountLeavesUpsertedAtAlone(t *testing.T) {
+	// arrange
+	migrations, err := Load(files)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	var up string
+	for _, migration := range migrations {
+		if migration.Name == "add_users_follower_count" {
+			up = migration.Up
+		}
+	}
+
+	// act
+	guard := strings.Index(up, "when (old.follower_count is not distinct from new.follower_count)")
+	backfill := strings.Index(up, "update users u set follower_count")
+
+	// assert
+	if guard == -1 {
+		t.Fatalf("expected users_set_upserted_at to skip follower count updates")
+	}
+	if backfill < guard {
+		t.Errorf("expected the follower count backfill to run after the trigger is guarded")
+	}
+}
+
+func TestUp_AppliesPendingMigrations(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	expectLocked(mockDb, 1)
+	mockDb.ExpectExec("create table posts").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mockDb.ExpectExec("insert into schema_migrations").WithArgs(2, "create_posts").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockDb.ExpectCommit()
+
+	m := &migrator{db: mockDb, migrations: testMigrations}
+
+	// act
+	err = m.Up(context.Background())
+
+	// assert
+	if err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUp_RollsBackOnError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	expectLocked(mockDb)
+	mockDb.ExpectExec("create table users").WillReturnError(errors.New("syntax error"))
+	mockDb.ExpectRollback()
+
+	m := &migrator{db: mockDb, migrations: testMigrations}
+
+	// act
+	err = m.Up(context.Background())
+
+	// assert
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDown_RevertsNewestMigrations(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	expectLocked(mockDb, 1, 2)
+	mockDb.ExpectExec("drop table posts").WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	mockDb.ExpectExec("delete from schema_migrations").WithArgs(2).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mockDb.ExpectCommit()
+
+	m := &migrator{db: mockDb, migrations: testMigrations}
+
+	// act
+	err = m.Down(context.Background(), 1)
+
+	// assert
+	if err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,2 @@
+drop table users;
+drop function set_upserted_at();
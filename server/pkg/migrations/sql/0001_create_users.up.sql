@@ -0,0 +1,35 @@
+-- users predates the migrations, so this baseline also has to apply cleanly
+-- against a database that already has it.
+create table if not exists users (
+	id integer primary key generated always as identity,
+	name text not null,
+	email text not null,
+	bio text not null default '',
+	dob date,
+	upserted_at timestamptz not null default now()
+);
+
+do $$
+begin
+	if not exists (select 1 from pg_constraint where conname = 'users_email_key' and conrelid = 'users'::regclass) then
+		alter table users add constraint users_email_key unique (email);
+	end if;
+end;
+$$;
+
+create or replace function set_upserted_at() returns trigger as $$
+begin
+	new.upserted_at = now();
+	return new;
+end;
+$$ language plpgsql;
+
+do $$
+begin
+	if not exists (select 1 from pg_trigger where tgname = 'users_set_upserted_at' and tgrelid = 'users'::regclass) then
+		create trigger users_set_upserted_at
+			before update on users
+			for each row execute function set_upserted_at();
+	end if;
+end;
+$$;
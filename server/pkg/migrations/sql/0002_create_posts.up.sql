@@ -0,0 +1,8 @@
+create table posts (
+	id integer primary key generated always as identity,
+	author_id integer not null references users (id) on delete cascade,
+	text text not null,
+	created_at timestamptz not null default now()
+);
+
+create index posts_author_id_created_at_idx on posts (author_id, created_at desc, id desc);
@@ -0,0 +1,9 @@
+create table follows (
+	follower_id integer not null references users (id) on delete cascade,
+	followee_id integer not null references users (id) on delete cascade,
+	created_at timestamptz not null default now(),
+	primary key (follower_id, followee_id),
+	constraint follows_no_self_follow check (follower_id <> followee_id)
+);
+
+create index follows_followee_id_idx on follows (followee_id);
@@ -0,0 +1 @@
+drop table timeline_entries;
@@ -0,0 +1,8 @@
+create table timeline_entries (
+	user_id integer not null references users (id) on delete cascade,
+	post_id integer not null references posts (id) on delete cascade,
+	created_at timestamptz not null,
+	primary key (user_id, post_id)
+);
+
+create index timeline_entries_user_id_created_at_idx on timeline_entries (user_id, created_at desc, post_id desc);
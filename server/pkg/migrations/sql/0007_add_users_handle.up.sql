@@ -0,0 +1,7 @@
+alter table users add column handle text;
+
+update users set handle = 'user' || id;
+
+alter table users alter column handle set not null;
+
+create unique index users_handle_key on users (lower(handle));
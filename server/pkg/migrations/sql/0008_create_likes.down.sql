@@ -0,0 +1,3 @@
+drop table likes;
+drop function update_post_like_count();
+alter table posts drop column like_count;
@@ -0,0 +1,25 @@
+create table likes (
+	post_id integer not null references posts (id) on delete cascade,
+	user_id integer not null references users (id) on delete cascade,
+	created_at timestamptz not null default now(),
+	primary key (post_id, user_id)
+);
+
+create index likes_user_id_idx on likes (user_id);
+
+alter table posts add column like_count integer not null default 0;
+
+create function update_post_like_count() returns trigger as $$
+begin
+	if tg_op = 'INSERT' then
+		update posts set like_count = like_count + 1 where id = new.post_id;
+	else
+		update posts set like_count = like_count - 1 where id = old.post_id;
+	end if;
+	return null;
+end;
+$$ language plpgsql;
+
+create trigger likes_update_post_like_count
+	after insert or delete on likes
+	for each row execute function update_post_like_count();
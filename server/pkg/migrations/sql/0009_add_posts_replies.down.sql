@@ -0,0 +1,2 @@
+alter table posts drop column root_id;
+alter table posts drop column parent_id;
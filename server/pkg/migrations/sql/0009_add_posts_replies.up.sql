@@ -0,0 +1,4 @@
+alter table posts add column parent_id integer references posts (id) on delete set null;
+alter table posts add column root_id integer references posts (id) on delete set null;
+
+create index posts_parent_id_idx on posts (parent_id);
@@ -0,0 +1,7 @@
+drop trigger posts_update_repost_count on posts;
+drop function update_post_repost_count();
+delete from posts where kind = 'repost';
+alter table posts drop column repost_count;
+alter table posts drop column quote_of_id;
+alter table posts drop column repost_of_id;
+alter table posts drop column kind;
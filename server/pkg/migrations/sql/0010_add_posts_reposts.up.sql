@@ -0,0 +1,25 @@
+alter table posts add column kind text not null default 'post';
+alter table posts add column repost_of_id integer references posts (id) on delete cascade;
+alter table posts add column quote_of_id integer references posts (id) on delete set null;
+alter table posts add column repost_count integer not null default 0;
+
+alter table posts add constraint posts_kind_check check (kind in ('post', 'repost', 'quote'));
+alter table posts add constraint posts_repost_of_id_check check ((kind = 'repost') = (repost_of_id is not null));
+alter table posts add constraint posts_repost_key unique (author_id, repost_of_id);
+
+create index posts_repost_of_id_idx on posts (repost_of_id);
+
+create function update_post_repost_count() returns trigger as $$
+begin
+	if tg_op = 'INSERT' and new.repost_of_id is not null then
+		update posts set repost_count = repost_count + 1 where id = new.repost_of_id;
+	elsif tg_op = 'DELETE' and old.repost_of_id is not null then
+		update posts set repost_count = repost_count - 1 where id = old.repost_of_id;
+	end if;
+	return null;
+end;
+$$ language plpgsql;
+
+create trigger posts_update_repost_count
+	after insert or delete on posts
+	for each row execute function update_post_repost_count();
@@ -0,0 +1,4 @@
+drop table post_mentions;
+drop table post_hashtags;
+drop table hashtags;
+alter table posts drop column entities;
@@ -0,0 +1,25 @@
+-- posts written before this migration keep an empty list of entities
+alter table posts add column entities jsonb not null default '[]';
+
+create table hashtags (
+	id integer primary key generated always as identity,
+	name text not null,
+	constraint hashtags_name_key unique (name)
+);
+
+create table post_hashtags (
+	post_id integer not null references posts (id) on delete cascade,
+	hashtag_id integer not null references hashtags (id) on delete cascade,
+	created_at timestamptz not null,
+	primary key (post_id, hashtag_id)
+);
+
+create index post_hashtags_hashtag_id_created_at_idx on post_hashtags (hashtag_id, created_at desc);
+
+create table post_mentions (
+	post_id integer not null references posts (id) on delete cascade,
+	user_id integer not null references users (id) on delete cascade,
+	primary key (post_id, user_id)
+);
+
+create index post_mentions_user_id_idx on post_mentions (user_id);
@@ -0,0 +1,2 @@
+alter table posts drop column search;
+alter table users drop column search;
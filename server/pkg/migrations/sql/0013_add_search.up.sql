@@ -0,0 +1,11 @@
+-- the simple configuration neither stems nor drops stop words, so that names,
+-- handles and posts in any language are matched as they are written
+alter table users add column search tsvector generated always as (
+	setweight(to_tsvector('simple', handle), 'A') || setweight(to_tsvector('simple', name), 'B')
+) stored;
+
+create index users_search_idx on users using gin (search);
+
+alter table posts add column search tsvector generated always as (to_tsvector('simple', text)) stored;
+
+create index posts_search_idx on posts using gin (search);
@@ -0,0 +1 @@
+drop index post_hashtags_created_at_idx;
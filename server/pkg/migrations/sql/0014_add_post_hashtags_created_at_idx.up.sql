@@ -0,0 +1,3 @@
+-- trends are computed from the hashtags used within a recent window, whatever
+-- the hashtag
+create index post_hashtags_created_at_idx on post_hashtags (created_at);
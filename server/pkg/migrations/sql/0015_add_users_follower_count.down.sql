@@ -0,0 +1,10 @@
+drop trigger follows_update_user_follower_count on follows;
+drop function update_user_follower_count();
+
+drop trigger users_set_upserted_at on users;
+
+create trigger users_set_upserted_at
+	before update on users
+	for each row execute function set_upserted_at();
+
+alter table users drop column follower_count;
@@ -0,0 +1,30 @@
+-- the follower count decides whether an author's posts are fanned out on write,
+-- so it is kept up to date rather than counted on every read
+alter table users add column follower_count integer not null default 0;
+
+-- upserted_at is the user's version, so a follower count change is not a
+-- profile edit and must leave it alone
+drop trigger users_set_upserted_at on users;
+
+create trigger users_set_upserted_at
+	before update on users
+	for each row
+	when (old.follower_count is not distinct from new.follower_count)
+	execute function set_upserted_at();
+
+update users u set follower_count = (select count(*) from follows f where f.followee_id = u.id);
+
+create function update_user_follower_count() returns trigger as $$
+begin
+	if tg_op = 'INSERT' then
+		update users set follower_count = follower_count + 1 where id = new.followee_id;
+	else
+		update users set follower_count = follower_count - 1 where id = old.followee_id;
+	end if;
+	return null;
+end;
+$$ language plpgsql;
+
+create trigger follows_update_user_follower_count
+	after insert or delete on follows
+	for each row execute function update_user_follower_count();
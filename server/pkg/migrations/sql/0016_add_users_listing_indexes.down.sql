@@ -0,0 +1,3 @@
+drop index users_lower_name_idx;
+drop index users_upserted_at_id_idx;
+drop index users_name_id_idx;
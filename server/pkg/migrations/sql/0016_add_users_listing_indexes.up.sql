@@ -0,0 +1,5 @@
+-- user listing pages through users by (name, id) or (upserted_at, id) and
+-- filters by a case-insensitive name prefix
+create index users_name_id_idx on users (name, id);
+create index users_upserted_at_id_idx on users (upserted_at, id);
+create index users_lower_name_idx on users (lower(name) text_pattern_ops);
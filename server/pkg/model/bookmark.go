@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// BookmarkedPost is a post saved by a user, with the time it was saved.
+type BookmarkedPost struct {
+	Post
+	BookmarkedAt time.Time `db:"bookmarked_at" json:"bookmarkedAt"`
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the ISO-8601 calendar date format dates are written in.
+const DateLayout = "2006-01-02"
+
+// dateLayouts are the formats dates are read in: ISO-8601, and the DD-MM-YYYY
+// format the API accepted first.
+var dateLayouts = []string{DateLayout, "2-1-2006"}
+
+var ErrInvalidDate = errors.New("date should be formatted as YYYY-MM-DD or DD-MM-YYYY")
+
+// Date is a calendar date without a time of day, such as a date of birth. It
+// is stored as a Postgres date and sent to clients as YYYY-MM-DD.
+type Date struct {
+	time.Time
+}
+
+// ParseDate reads a date in any of the accepted formats, rejecting dates that
+// are not on the calendar such as 31-02-2000.
+func ParseDate(s string) (Date, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Date{t}, nil
+		}
+	}
+
+	return Date{}, ErrInvalidDate
+}
+
+// parseDateTime adapts ParseDate to the validate date rules.
+func parseDateTime(s string) (time.Time, error) {
+	date, err := ParseDate(s)
+	return date.Time, err
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrInvalidDate
+	}
+
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = date
+	return nil
+}
+
+// Scan implements sql.Scanner so dates can be read from date columns.
+func (d *Date) Scan(src any) error {
+	t, ok := src.(time.Time)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into model.Date", src)
+	}
+
+	*d = Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+	return nil
+}
+
+// Value implements driver.Valuer so dates can be written to date columns.
+func (d Date) Value() (driver.Value, error) {
+	return d.Time, nil
+}
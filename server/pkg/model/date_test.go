@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDate_AcceptsBothFormats(t *testing.T) {
+	expected := time.Date(1965, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	for _, s := range []string{"1965-03-15", "15-03-1965", "15-3-1965"} {
+		actual, err := ParseDate(s)
+		if err != nil || !actual.Equal(expected) {
+			t.Errorf("%q: expected: %+v, actual: %+v, error: %+v", s, expected, actual, err)
+		}
+	}
+}
+
+func TestParseDate_Invalid_ReturnsError(t *testing.T) {
+	for _, s := range []string{"", "99-99-0", "-1-5-2000", "1965-02-30", "15/03/1965"} {
+		if _, err := ParseDate(s); !errors.Is(err, ErrInvalidDate) {
+			t.Errorf("%q: expected: %+v, actual: %+v", s, ErrInvalidDate, err)
+		}
+	}
+}
+
+func TestDate_MarshalsAsISO8601(t *testing.T) {
+	date := Date{time.Date(1965, time.March, 15, 0, 0, 0, 0, time.UTC)}
+
+	actual, err := json.Marshal(User{DOB: &date})
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	var body map[string]any
+	json.Unmarshal(actual, &body)
+	if body["dob"] != "1965-03-15" {
+		t.Errorf("expected: %+v, actual: %+v", "1965-03-15", body["dob"])
+	}
+}
+
+func TestDate_UnmarshalJSON_RoundTrips(t *testing.T) {
+	var date Date
+	if err := json.Unmarshal([]byte(`"15-03-1965"`), &date); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if date.String() != "1965-03-15" {
+		t.Errorf("expected: %+v, actual: %+v", "1965-03-15", date.String())
+	}
+}
+
+func TestDate_Scan_DropsTimeOfDay(t *testing.T) {
+	var date Date
+	if err := date.Scan(time.Date(1965, time.March, 15, 13, 30, 0, 0, time.Local)); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if date.String() != "1965-03-15" || date.Location() != time.UTC {
+		t.Errorf("expected: %+v, actual: %+v", "1965-03-15 UTC", date.Time)
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+const (
+	EntityHashtag = "hashtag"
+	EntityMention = "mention"
+)
+
+// Entity is a hashtag or a mention found in the text of a post. Start and End
+// are offsets in Unicode code points, End exclusive, and cover the leading #
+// or @. Text is the hashtag or handle without it, normalized: hashtags are
+// lower case in Unicode normal form C and handles are lower case. UserID is
+// the user a mention refers to.
+type Entity struct {
+	Type string `json:"type"`
+	Start int `json:"start"`
+	End int `json:"end"`
+	Text string `json:"text"`
+	UserID *int `json:"userId,omitempty"`
+}
+
+// Hashtags returns the distinct hashtags among entities.
+func Hashtags(entities []Entity) []string {
+	seen := map[string]bool{}
+	hashtags := []string{}
+	for _, entity := range entities {
+		if entity.Type == EntityHashtag && !seen[entity.Text] {
+			seen[entity.Text] = true
+			hashtags = append(hashtags, entity.Text)
+		}
+	}
+
+	return hashtags
+}
+
+// MentionedUserIDs returns the distinct users mentioned among entities.
+func MentionedUserIDs(entities []Entity) []int {
+	seen := map[int]bool{}
+	ids := []int{}
+	for _, entity := range entities {
+		if entity.Type == EntityMention && entity.UserID != nil && !seen[*entity.UserID] {
+			seen[*entity.UserID] = true
+			ids = append(ids, *entity.UserID)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MinHandleLength = 3
+	MaxHandleLength = 15
+)
+
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_]*$`)
+
+// reservedHandles cannot be taken by anyone, so that they stay free for routes
+// and for accounts run by the site itself.
+var reservedHandles = map[string]bool{
+	"about": true,
+	"admin": true,
+	"administrator": true,
+	"api": true,
+	"explore": true,
+	"help": true,
+	"home": true,
+	"login": true,
+	"logout": true,
+	"me": true,
+	"moderator": true,
+	"notifications": true,
+	"root": true,
+	"search": true,
+	"settings": true,
+	"signup": true,
+	"support": true,
+	"system": true,
+}
+
+// HandleAvailability is the answer to whether a handle can be registered.
+// Reason explains why it cannot.
+type HandleAvailability struct {
+	Handle string `json:"handle"`
+	Available bool `json:"available"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ValidHandle is a validate.Rule for handles: 3 to 15 letters, digits or
+// underscores, not one of the reserved words. Handles are compared without
+// regard to case.
+func ValidHandle(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	if length := utf8.RuneCountInString(value); length < MinHandleLength || length > MaxHandleLength {
+		return fmt.Sprintf("should be %d to %d characters", MinHandleLength, MaxHandleLength)
+	}
+
+	if !handlePattern.MatchString(value) {
+		return "should only contain letters, digits and underscores"
+	}
+
+	if reservedHandles[strings.ToLower(value)] {
+		return "is reserved"
+	}
+
+	return ""
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Optional is a field of a JSON merge patch (RFC 7396). It tells apart a field
+// that was left out, which leaves the value unchanged, from one set to null,
+// which removes it, and from one set to a value.
+type Optional[T any] struct {
+	Set bool
+	Null bool
+	Value T
+}
+
+// Some returns an Optional set to value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Set: true, Value: value}
+}
+
+// Null returns an Optional set to null.
+func Null[T any]() Optional[T] {
+	return Optional[T]{Set: true, Null: true}
+}
+
+// UnmarshalJSON is only called for fields present in the document, so reaching
+// it at all means the field was set.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(o.Value)
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptional_UnmarshalJSON_TellsAbsentFromNull(t *testing.T) {
+	var patch UpdateUser
+	if err := json.Unmarshal([]byte(`{"name": "Michael", "bio": null}`), &patch); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if patch.Name != Some("Michael") {
+		t.Errorf("expected: %+v, actual: %+v", Some("Michael"), patch.Name)
+	}
+
+	if patch.Bio != Null[string]() {
+		t.Errorf("expected: %+v, actual: %+v", Null[string](), patch.Bio)
+	}
+
+	if patch.Email.Set || patch.DOB.Set {
+		t.Errorf("expected absent fields to be unset, actual: %+v", patch)
+	}
+}
+
+func TestOptional_UnmarshalJSON_WrongType_ReturnsError(t *testing.T) {
+	var patch UpdateUser
+	if err := json.Unmarshal([]byte(`{"bio": 42}`), &patch); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is one slice of a paginated list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// PageLimit clamps a requested page size to (0, MaxPageLimit], falling back to
+// DefaultPageLimit when none was requested.
+func PageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+
+	if limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+
+	return limit
+}
+
+// FetchPage reads the page of at most limit items that fetch returns. fetch is
+// asked for one more item than that, whose presence means there is a next
+// page; it is dropped and cursor is called with the last item kept to produce
+// NextCursor.
+func FetchPage[T any](limit int, fetch func(limit int) ([]T, error), cursor func(last T) string) (*Page[T], error) {
+	items, err := fetch(limit + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = cursor(page.Items[limit-1])
+	}
+
+	return page, nil
+}
+
+// MapPage converts the items of page with f, keeping its cursor.
+func MapPage[T, U any](page *Page[T], f func(T) U) *Page[U] {
+	items := make([]U, len(page.Items))
+	for i, item := range page.Items {
+		items[i] = f(item)
+	}
+
+	return &Page[U]{Items: items, NextCursor: page.NextCursor}
+}
+
+// Cursor marks a position in a list ordered by creation time and then id, both
+// descending.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID int `json:"i"`
+}
+
+// String encodes the cursor into the opaque form handed out to clients.
+func (c Cursor) String() string {
+	return encodeCursor(c)
+}
+
+// ParseCursor decodes a cursor produced by Cursor.String. An empty string
+// yields a nil cursor, meaning the start of the list.
+func ParseCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var cursor Cursor
+	if err := decodeCursor(s, &cursor); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+func encodeCursor(v any) string {
+	raw, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
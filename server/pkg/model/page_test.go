@@ -0,0 +1,84 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCursor_RoundTrips(t *testing.T) {
+	expected := Cursor{CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: 42}
+
+	actual, err := ParseCursor(expected.String())
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if actual.ID != expected.ID || !actual.CreatedAt.Equal(expected.CreatedAt) {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+}
+
+func TestParseCursor_Empty_ReturnsNil(t *testing.T) {
+	actual, err := ParseCursor("")
+	if actual != nil || err != nil {
+		t.Errorf("expected nil cursor and error, actual: %+v, %+v", actual, err)
+	}
+}
+
+func TestParseCursor_Invalid_ReturnsError(t *testing.T) {
+	for _, cursor := range []string{"%%%", "bm9jb2xvbg", "YTox"} {
+		if _, err := ParseCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("cursor %q: expected: %+v, actual: %+v", cursor, ErrInvalidCursor, err)
+		}
+	}
+}
+
+func TestFetchPage_MoreItems_SetsNextCursor(t *testing.T) {
+	var requested int
+	fetch := func(limit int) ([]int, error) {
+		requested = limit
+		return []int{1, 2, 3}, nil
+	}
+
+	page, err := FetchPage(2, fetch, func(last int) string { return strconv.Itoa(last) })
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if requested != 3 {
+		t.Errorf("expected: %d, actual: %d", 3, requested)
+	}
+
+	if len(page.Items) != 2 || page.NextCursor != "2" {
+		t.Errorf("expected items [1 2] and cursor 2, actual: %+v", page)
+	}
+}
+
+func TestFetchPage_LastPage_LeavesCursorEmpty(t *testing.T) {
+	fetch := func(limit int) ([]int, error) {
+		return []int{1, 2}, nil
+	}
+
+	page, _ := FetchPage(2, fetch, func(last int) string { return strconv.Itoa(last) })
+	if len(page.Items) != 2 || page.NextCursor != "" {
+		t.Errorf("expected items [1 2] and no cursor, actual: %+v", page)
+	}
+}
+
+func TestMapPage_KeepsCursor(t *testing.T) {
+	page := MapPage(&Page[int]{Items: []int{1, 2}, NextCursor: "2"}, strconv.Itoa)
+	if len(page.Items) != 2 || page.Items[1] != "2" || page.NextCursor != "2" {
+		t.Errorf("expected items [1 2] and cursor 2, actual: %+v", page)
+	}
+}
+
+func TestPageLimit_Clamps(t *testing.T) {
+	cases := map[int]int{0: DefaultPageLimit, -5: DefaultPageLimit, 10: 10, MaxPageLimit + 1: MaxPageLimit}
+	for limit, expected := range cases {
+		if actual := PageLimit(limit); actual != expected {
+			t.Errorf("limit %d: expected: %d, actual: %d", limit, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// Kinds of post. A repost shares another post as it is and has no text of its
+// own, while a quote shares it with commentary.
+const (
+	PostKindPost   = "post"
+	PostKindRepost = "repost"
+	PostKindQuote  = "quote"
+)
+
+type Post struct {
+	ID int `db:"id" json:"id"`
+	AuthorID int `db:"author_id" json:"authorId"`
+	Text string `db:"text" json:"text"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	LikeCount int `db:"like_count" json:"likeCount"`
+	// ParentID is the post this one replies to and RootID the post that started
+	// the conversation. Both are nil for posts that are not replies, and become
+	// nil when the post they point to is deleted.
+	ParentID *int `db:"parent_id" json:"parentId,omitempty"`
+	RootID *int `db:"root_id" json:"rootId,omitempty"`
+	Kind string `db:"kind" json:"kind"`
+	// RepostOfID is the post a repost shares and QuoteOfID the one a quote
+	// comments on. Original holds that post when it is read along.
+	RepostOfID *int `db:"repost_of_id" json:"repostOfId,omitempty"`
+	QuoteOfID *int `db:"quote_of_id" json:"quoteOfId,omitempty"`
+	Original *Post `db:"-" json:"original,omitempty"`
+	RepostCount int `db:"repost_count" json:"repostCount"`
+	Entities []Entity `db:"entities" json:"entities"`
+	// Liked tells whether the viewer likes the post. It is only set when the post
+	// is read by an authenticated user.
+	Liked *bool `db:"-" json:"liked,omitempty"`
+}
+
+// PostPointers returns pointers to the elements of posts, for functions that
+// update posts in place.
+func PostPointers(posts []Post) []*Post {
+	ptrs := make([]*Post, len(posts))
+	for i := range posts {
+		ptrs[i] = &posts[i]
+	}
+
+	return ptrs
+}
+
+type CreatePost struct {
+	Text string `json:"text"`
+	ParentID *int `json:"parentId"`
+	QuoteOfID *int `json:"quoteOfId"`
+}
+
+// PostLikes is how many likes a post has and whether the viewer is one of them,
+// along with its repost count, which changes just as often.
+type PostLikes struct {
+	PostID int `db:"post_id" json:"postId"`
+	LikeCount int `db:"like_count" json:"likeCount"`
+	RepostCount int `db:"repost_count" json:"repostCount"`
+	Liked bool `db:"liked" json:"liked"`
+}
@@ -0,0 +1,51 @@
+package model
+
+const (
+	SearchUsers = "users"
+	SearchPosts = "posts"
+)
+
+// SearchedUser is a user matching a search, along with how well it matches.
+type SearchedUser struct {
+	User
+	Rank float32 `db:"rank" json:"-"`
+}
+
+// SearchedPost is a post matching a search, along with how well it matches.
+type SearchedPost struct {
+	Post
+	Rank float32 `db:"rank" json:"-"`
+}
+
+// SearchCursor marks a position in search results, ordered by rank and then id,
+// both descending. It remembers the search it was issued for since ranks mean
+// nothing for another one.
+type SearchCursor struct {
+	Type string `json:"t"`
+	Query string `json:"q"`
+	Rank float32 `json:"r"`
+	ID int `json:"i"`
+}
+
+func (c SearchCursor) String() string {
+	return encodeCursor(c)
+}
+
+// ParseSearchCursor decodes a cursor produced by SearchCursor.String and checks
+// it was issued for the same search. An empty string yields a nil cursor.
+func ParseSearchCursor(s, searchType, query string) (*SearchCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var cursor SearchCursor
+	if err := decodeCursor(s, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.Type != searchType || cursor.Query != query {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,51 @@
+package model
+
+// Thread is a post in the context of its conversation: the chain of posts it
+// replies to, oldest first, and a page of the replies below it.
+type Thread struct {
+	Ancestors []Post `json:"ancestors"`
+	Post Post `json:"post"`
+	Replies Page[ThreadNode] `json:"replies"`
+}
+
+// ThreadNode is a reply together with the replies to it on the same page.
+type ThreadNode struct {
+	Post
+	Replies []ThreadNode `json:"replies"`
+}
+
+// ThreadPost is a descendant of a post. Path holds the ids leading to it from
+// the post, itself included. Since ids grow over time, ordering by path lists
+// a conversation depth first with the oldest replies first.
+type ThreadPost struct {
+	Post
+	Path []int `db:"path" json:"-"`
+}
+
+// ThreadCursor marks a position in the depth-first list of a post's replies.
+type ThreadCursor struct {
+	Path []int `json:"p"`
+}
+
+func (c ThreadCursor) String() string {
+	return encodeCursor(c)
+}
+
+// ParseThreadCursor decodes a cursor produced by ThreadCursor.String. An empty
+// string yields a nil cursor, meaning the first reply.
+func ParseThreadCursor(s string) (*ThreadCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var cursor ThreadCursor
+	if err := decodeCursor(s, &cursor); err != nil {
+		return nil, err
+	}
+
+	if len(cursor.Path) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Trend is a hashtag used a lot lately. Score counts the posts using it within
+// a window, each weighing less the older it is.
+type Trend struct {
+	Hashtag string `db:"name" json:"hashtag"`
+	PostCount int `db:"post_count" json:"postCount"`
+	Score float64 `db:"score" json:"score"`
+}
+
+// Trends are the top trends over a window, as of UpdatedAt.
+type Trends struct {
+	Window string `json:"window"`
+	Trends []Trend `json:"trends"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
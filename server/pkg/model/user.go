@@ -1,27 +1,213 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"x/pkg/validate"
+)
+
+type UserSortField string
+
+const (
+	SortByName UserSortField = "name"
+	SortByUpsertedAt UserSortField = "upsertedAt"
+)
+
+const (
+	MaxNameLength = 100
+	MaxEmailLength = 254
+	MaxBioLength = 160
+	MinPasswordLength = 8
+	// bcrypt ignores everything past 72 bytes
+	MaxPasswordBytes = 72
+)
+
+var ErrInvalidETag = errors.New("invalid entity tag")
+
+var ErrInvalidSort = errors.New("sort should be one of name, -name, upsertedAt or -upsertedAt")
 
 type User struct {
 	ID int `db:"id" json:"id"`
 	Name string `db:"name" json:"name"`
-	Email string `db:"email" json:"email"`
+	Handle string `db:"handle" json:"handle"`
+	// Email is private: it is only sent to the user it belongs to.
+	Email string `db:"email" json:"email,omitempty"`
 	Bio string `db:"bio" json:"bio"`
-	DOB *time.Time `db:"dob" json:"dob"`
+	DOB *Date `db:"dob" json:"dob"`
 	UpsertedAt time.Time `db:"upserted_at" json:"upsertedAt"`
 }
 
+// ETag identifies the stored version of the user. upserted_at changes on
+// every update, so it serves as the version.
+func (u User) ETag() string {
+	return `"` + strconv.FormatInt(u.UpsertedAt.UnixMicro(), 36) + `"`
+}
+
+// ParseETag reads the version out of an entity tag produced by User.ETag.
+func ParseETag(etag string) (time.Time, error) {
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return time.Time{}, ErrInvalidETag
+	}
+
+	micros, err := strconv.ParseInt(etag[1:len(etag)-1], 36, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidETag
+	}
+
+	return time.UnixMicro(micros), nil
+}
+
 type CreateUser struct {
 	Name string `json:"name"`
+	Handle string `json:"handle"`
 	Email string `json:"email"`
 	Bio string `json:"bio"`
 	DOB string `json:"dob"`
+	Password string `json:"password"`
 }
 
-type UpdateUser struct {
-	ID int `json:"id"`
-	Name string `json:"name"`
+type Login struct {
 	Email string `json:"email"`
-	Bio interface{} `json:"bio"`
-	DOB string `json:"dob"`
+	Password string `json:"password"`
+}
+
+type Session struct {
+	Token string `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User *User `json:"user"`
+}
+
+// Credentials are the stored login details of a user. They are never returned
+// to clients.
+type Credentials struct {
+	ID int `db:"id"`
+	PasswordHash string `db:"password_hash"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+// UpdateUser is a JSON merge patch (RFC 7396) of a user's profile. Fields left
+// out are unchanged and null clears the name, bio or dob. The handle and email
+// identify the user, so they cannot be cleared.
+type UpdateUser struct {
+	Name Optional[string] `json:"name"`
+	Handle Optional[string] `json:"handle"`
+	Email Optional[string] `json:"email"`
+	Bio Optional[string] `json:"bio"`
+	DOB Optional[string] `json:"dob"`
+}
+
+// Validate checks the fields of a registration, returning validate.Errors
+// listing every problem found.
+func (c CreateUser) Validate() error {
+	errs := validate.Errors{}
+	errs.Field("name", c.Name, validate.Required, validate.MaxLength(MaxNameLength))
+	errs.Field("handle", c.Handle, validate.Required, ValidHandle)
+	errs.Field("email", c.Email, validate.Required, validate.MaxLength(MaxEmailLength), validate.Email)
+	errs.Field("bio", c.Bio, validate.MaxLength(MaxBioLength))
+	errs.Field("dob", c.DOB, validate.Date(parseDateTime), validate.NotInFuture(parseDateTime))
+	errs.Field("password", c.Password, validate.Required, validate.MinLength(MinPasswordLength), validate.MaxBytes(MaxPasswordBytes))
+
+	return errs.Err()
+}
+
+// Validate checks the fields set by a profile update.
+func (u UpdateUser) Validate() error {
+	errs := validate.Errors{}
+	errs.Field("name", u.Name.Value, validate.MaxLength(MaxNameLength))
+	errs.Field("bio", u.Bio.Value, validate.MaxLength(MaxBioLength))
+	errs.Field("dob", u.DOB.Value, validate.Date(parseDateTime), validate.NotInFuture(parseDateTime))
+
+	if u.Handle.Null {
+		errs.Add("handle", "cannot be removed")
+	} else if u.Handle.Set {
+		errs.Field("handle", u.Handle.Value, validate.Required, ValidHandle)
+	}
+
+	if u.Email.Null {
+		errs.Add("email", "cannot be removed")
+	} else if u.Email.Set {
+		errs.Field("email", u.Email.Value, validate.Required, validate.MaxLength(MaxEmailLength), validate.Email)
+	}
+
+	return errs.Err()
+}
+
+// ListUsers holds the raw query parameters for listing users.
+type ListUsers struct {
+	NamePrefix string
+	Sort string
+	Cursor string
+	Limit int
+}
+
+// UserQuery is a validated ListUsers as understood by the repository.
+type UserQuery struct {
+	NamePrefix string
+	SortBy UserSortField
+	Descending bool
+	After *UserCursor
+	Limit int
+}
+
+// ParseUserSort parses a sort parameter such as "name" or "-upsertedAt", where
+// a leading "-" means descending. An empty string sorts by name ascending.
+func ParseUserSort(sort string) (UserSortField, bool, error) {
+	if sort == "" {
+		return SortByName, false, nil
+	}
+
+	descending := strings.HasPrefix(sort, "-")
+	field := UserSortField(strings.TrimPrefix(sort, "-"))
+	if field != SortByName && field != SortByUpsertedAt {
+		return "", false, ErrInvalidSort
+	}
+
+	return field, descending, nil
+}
+
+// UserCursor marks a position in a list of users. It remembers the sort it was
+// issued for so it cannot be replayed against a different ordering.
+type UserCursor struct {
+	SortBy UserSortField `json:"s"`
+	Descending bool `json:"d,omitempty"`
+	Name string `json:"n,omitempty"`
+	UpsertedAt time.Time `json:"u"`
+	ID int `json:"i"`
+}
+
+// NewUserCursor returns the cursor pointing just after user in the given sort.
+func NewUserCursor(user User, sortBy UserSortField, descending bool) UserCursor {
+	cursor := UserCursor{SortBy: sortBy, Descending: descending, ID: user.ID}
+	if sortBy == SortByName {
+		cursor.Name = user.Name
+	} else {
+		cursor.UpsertedAt = user.UpsertedAt
+	}
+
+	return cursor
+}
+
+func (c UserCursor) String() string {
+	return encodeCursor(c)
+}
+
+// ParseUserCursor decodes a cursor produced by UserCursor.String and checks it
+// was issued for the given sort. An empty string yields a nil cursor.
+func ParseUserCursor(s string, sortBy UserSortField, descending bool) (*UserCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var cursor UserCursor
+	if err := decodeCursor(s, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.SortBy != sortBy || cursor.Descending != descending {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
 }
\ No newline at end of file
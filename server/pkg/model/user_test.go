@@ -0,0 +1,127 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"x/pkg/validate"
+)
+
+func TestCreateUser_Validate_ReportsEveryField(t *testing.T) {
+	request := CreateUser{
+		Name: "",
+		Email: "not an email",
+		Bio: strings.Repeat("a", MaxBioLength+1),
+		DOB: "99-99-0",
+		Password: "short",
+	}
+
+	var errs validate.Errors
+	if err := request.Validate(); !errors.As(err, &errs) {
+		t.Fatalf("expected validate.Errors, actual: %+v", err)
+	}
+
+	for _, field := range []string{"name", "email", "bio", "dob", "password"} {
+		if len(errs[field]) == 0 {
+			t.Errorf("expected an error for %s, actual: %+v", field, errs)
+		}
+	}
+}
+
+func TestCreateUser_Validate_ValidRequest_ReturnsNil(t *testing.T) {
+	request := CreateUser{
+		Name: "Michael Scott",
+		Handle: "michael_scott",
+		Email: "michael@dundermifflin.com",
+		DOB: "15-03-1965",
+		Password: "password1",
+	}
+
+	if err := request.Validate(); err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+}
+
+func TestCreateUser_Validate_LongPassword_ReportsPassword(t *testing.T) {
+	request := CreateUser{
+		Name: "Michael Scott",
+		Handle: "michael_scott",
+		Email: "michael@dundermifflin.com",
+		Password: strings.Repeat("ä", MaxPasswordBytes/2+1),
+	}
+
+	var errs validate.Errors
+	if err := request.Validate(); !errors.As(err, &errs) {
+		t.Fatalf("expected validate.Errors, actual: %+v", err)
+	}
+
+	if len(errs["password"]) != 1 {
+		t.Errorf("expected an error for password, actual: %+v", errs)
+	}
+}
+
+func TestUpdateUser_Validate_SkipsUnsetFields(t *testing.T) {
+	if err := (UpdateUser{}).Validate(); err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+}
+
+func TestUpdateUser_Validate_AllowsClearingAllButEmail(t *testing.T) {
+	patch := UpdateUser{
+		Name: Null[string](),
+		Bio: Null[string](),
+		DOB: Null[string](),
+		Email: Null[string](),
+	}
+
+	var errs validate.Errors
+	if err := patch.Validate(); !errors.As(err, &errs) {
+		t.Fatalf("expected validate.Errors, actual: %+v", err)
+	}
+
+	if len(errs) != 1 || len(errs["email"]) == 0 {
+		t.Errorf("expected only an email error, actual: %+v", errs)
+	}
+}
+
+func TestUserETag_RoundTrips(t *testing.T) {
+	user := User{UpsertedAt: time.Now()}
+
+	actual, err := ParseETag(user.ETag())
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if !actual.Equal(user.UpsertedAt.Truncate(time.Microsecond)) {
+		t.Errorf("expected: %+v, actual: %+v", user.UpsertedAt, actual)
+	}
+}
+
+func TestParseETag_Invalid_ReturnsError(t *testing.T) {
+	for _, etag := range []string{"", `"`, "abc", `W/"abc"`, `"!!"`} {
+		if _, err := ParseETag(etag); !errors.Is(err, ErrInvalidETag) {
+			t.Errorf("%q: expected: %+v, actual: %+v", etag, ErrInvalidETag, err)
+		}
+	}
+}
+
+func TestValidHandle(t *testing.T) {
+	cases := map[string]bool{
+		"dwight": true,
+		"Jim_Halpert": true,
+		"abc": true,
+		"ab": false,
+		"abcdefghijklmnop": false,
+		"no spaces": false,
+		"no-dashes": false,
+		"admin": false,
+		"Admin": false,
+	}
+
+	for handle, valid := range cases {
+		if actual := ValidHandle(handle) == ""; actual != valid {
+			t.Errorf("%q: expected valid %v, actual: %v", handle, valid, actual)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"log"
+	"x/pkg/model"
+	"x/pkg/repository"
+)
+
+// Repost shares postID with userID's followers. Reposting a repost shares the
+// post it reposts.
+func (s *service) Repost(ctx context.Context, userID, postID int) (*model.Post, error) {
+	repost, err := s.db.Repost(ctx, userID, postID)
+	if err != nil {
+		log.Printf("error reposting post: %+v", err)
+		var conflict *repository.ConflictError
+		if errors.As(err, &conflict) && conflict.Constraint == repostConstraint {
+			return nil, ErrAlreadyReposted
+		}
+		return nil, domainError(err)
+	}
+
+	if s.timelines != nil {
+		if err := s.timelines.Publish(context.WithoutCancel(ctx), *repost); err != nil {
+			log.Printf("error publishing repost to timelines: %+v", err)
+		}
+	}
+
+	if err := s.addOriginals(ctx, userID, []*model.Post{repost}); err != nil {
+		return nil, err
+	}
+
+	return repost, nil
+}
+
+// Unrepost removes userID's repost of postID.
+func (s *service) Unrepost(ctx context.Context, userID, postID int) error {
+	id, err := s.db.DeleteRepost(ctx, userID, postID)
+	if err != nil {
+		log.Printf("error deleting repost: %+v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotReposted
+		}
+		return err
+	}
+
+	if s.timelines != nil {
+		if err := s.timelines.Retract(ctx, id); err != nil {
+			log.Printf("error retracting repost from timelines: %+v", err)
+		}
+	}
+
+	return nil
+}
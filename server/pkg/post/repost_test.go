@@ -0,0 +1,150 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/repository"
+	"x/pkg/util"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRepost_ReturnsRepostWithOriginal(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockTimelines := &mockTimelines{}
+	service := New(mockRepo, mockTimelines)
+
+	originalID := 1
+	original := model.Post{ID: 1, AuthorID: 2, Text: "hello", Kind: model.PostKindPost, RepostCount: 1}
+	repost := &model.Post{ID: 5, AuthorID: 3, Kind: model.PostKindRepost, RepostOfID: &originalID, CreatedAt: time.Now()}
+
+	mockRepo.On("Repost", 3, 1).Return(repost, nil)
+	mockTimelines.On("Publish", *repost).Return(nil)
+	mockRepo.On("GetPosts", []int{1}).Return([]model.Post{original}, nil)
+	mockRepo.On("GetPostLikes", 3, []int{1}).Return([]model.PostLikes{{PostID: 1, RepostCount: 1}}, nil)
+
+	actual, err := service.Repost(context.Background(), 3, 1)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	notLiked := false
+	original.Liked = &notLiked
+	expected := *repost
+	expected.Original = &original
+
+	util.AssertJSON(actual, &expected, t)
+	mockRepo.AssertExpectations(t)
+	mockTimelines.AssertExpectations(t)
+}
+
+func TestRepost_Duplicate_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	conflict := &repository.ConflictError{Constraint: "posts_repost_key", Err: errors.New("duplicate key")}
+	mockRepo.On("Repost", 3, 1).Return((*model.Post)(nil), conflict)
+
+	_, actual := service.Repost(context.Background(), 3, 1)
+	if !errors.Is(actual, ErrAlreadyReposted) {
+		t.Errorf("expected: %+v, actual: %+v", ErrAlreadyReposted, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRepost_NoPost_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	mockRepo.On("Repost", 3, 1).Return((*model.Post)(nil), repository.ErrNotFound)
+
+	_, actual := service.Repost(context.Background(), 3, 1)
+	if !errors.Is(actual, ErrPostNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrPostNotFound, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUnrepost_RetractsRepost(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockTimelines := &mockTimelines{}
+	service := New(mockRepo, mockTimelines)
+
+	mockRepo.On("DeleteRepost", 3, 1).Return(5, nil)
+	mockTimelines.On("Retract", 5).Return(nil)
+
+	actual := service.Unrepost(context.Background(), 3, 1)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockTimelines.AssertExpectations(t)
+}
+
+func TestUnrepost_NotReposted_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockTimelines := &mockTimelines{}
+	service := New(mockRepo, mockTimelines)
+
+	mockRepo.On("DeleteRepost", 3, 1).Return(0, repository.ErrNotFound)
+
+	actual := service.Unrepost(context.Background(), 3, 1)
+	if !errors.Is(actual, ErrNotReposted) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotReposted, actual)
+	}
+
+	mockTimelines.AssertNotCalled(t, "Retract", mock.Anything)
+}
+
+func TestCreatePost_NoQuoted_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	quoteOfID := 9
+	mockRepo.On("CreatePost", 2, "hello", (*int)(nil), &quoteOfID, []model.Entity{}).Return((*model.Post)(nil), &repository.ReferenceError{Constraint: quoteConstraint, Err: errors.New("test error")})
+
+	_, actual := service.CreatePost(context.Background(), 2, "hello", nil, &quoteOfID)
+	if !errors.Is(actual, ErrQuotedNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrQuotedNotFound, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTimeline_AddsOriginalsOfReposts(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	originalID := 1
+	dummyTime := time.Now()
+	posts := []model.Post{
+		{ID: 5, AuthorID: 3, Kind: model.PostKindRepost, RepostOfID: &originalID, CreatedAt: dummyTime},
+		{ID: 4, AuthorID: 3, Text: "hi", Kind: model.PostKindPost, CreatedAt: dummyTime.Add(-time.Minute)},
+	}
+
+	mockRepo.On("GetTimeline", 7, (*model.Cursor)(nil), model.DefaultPageLimit+1).Return(posts, nil)
+	mockRepo.On("GetPostLikes", 7, []int{5, 4}).Return([]model.PostLikes{}, nil)
+	mockRepo.On("GetPosts", []int{1}).Return([]model.Post{{ID: 1, AuthorID: 2, Text: "hello", Kind: model.PostKindPost}}, nil)
+	mockRepo.On("GetPostLikes", 7, []int{1}).Return([]model.PostLikes{{PostID: 1, LikeCount: 1, Liked: true}}, nil)
+
+	actual, err := service.GetTimeline(context.Background(), 7, "", 0)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	repost := actual.Items[0]
+	if repost.AuthorID != 3 || repost.Original == nil || repost.Original.ID != 1 || !*repost.Original.Liked {
+		t.Errorf("expected post 1 reposted by user 3 and liked by the viewer, actual: %+v", repost)
+	}
+	if actual.Items[1].Original != nil {
+		t.Errorf("expected no original for a plain post, actual: %+v", actual.Items[1].Original)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
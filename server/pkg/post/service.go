@@ -0,0 +1,337 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+	"x/pkg/entity"
+	"x/pkg/model"
+	"x/pkg/repository"
+	"x/pkg/timeline"
+)
+
+// MaxPostLength is the maximum number of characters allowed in a post.
+const MaxPostLength = 280
+
+// repostConstraint is the unique constraint allowing one repost of a post per
+// user.
+const repostConstraint = "posts_repost_key"
+
+// the foreign keys a new post can violate
+const (
+	authorConstraint = "posts_author_id_fkey"
+	parentConstraint = "posts_parent_id_fkey"
+	quoteConstraint  = "posts_quote_of_id_fkey"
+)
+
+var (
+	ErrEmptyPost       = errors.New("post text cannot be empty")
+	ErrPostTooLong     = fmt.Errorf("post text cannot be longer than %d characters", MaxPostLength)
+	ErrPostNotFound    = errors.New("post not found")
+	ErrParentNotFound  = errors.New("post replied to not found")
+	ErrQuotedNotFound  = errors.New("post quoted not found")
+	ErrNotAuthor       = errors.New("only the author can delete a post")
+	ErrAuthorNotFound  = errors.New("author of the post not found")
+	ErrAlreadyReposted = errors.New("post is already reposted")
+	ErrNotReposted     = errors.New("post is not reposted")
+)
+
+type Service interface {
+	CreatePost(ctx context.Context, authorID int, text string, parentID, quoteOfID *int) (*model.Post, error)
+	GetPost(ctx context.Context, id, viewerID int) (*model.Post, error)
+	GetThread(ctx context.Context, id, viewerID int, cursor string, limit int) (*model.Thread, error)
+	DeletePost(ctx context.Context, id, userID int) error
+	Repost(ctx context.Context, userID, postID int) (*model.Post, error)
+	Unrepost(ctx context.Context, userID, postID int) error
+	Decorate(ctx context.Context, viewerID int, posts []*model.Post) error
+	GetTimeline(ctx context.Context, userID int, cursor string, limit int) (*model.Page[model.Post], error)
+}
+
+type service struct {
+	db repository.PostRepository
+	timelines timeline.Service
+}
+
+// New returns a post Service. When timelines is nil, home timelines are built
+// by joining posts and follows on every read.
+func New(db repository.PostRepository, timelines timeline.Service) Service {
+	return &service{
+		db: db,
+		timelines: timelines,
+	}
+}
+
+// CreatePost saves a post, as a reply to parentID and as a quote of quoteOfID
+// when they are not nil, along with the hashtags and mentions in its text.
+func (s *service) CreatePost(ctx context.Context, authorID int, text string, parentID, quoteOfID *int) (*model.Post, error) {
+	if err := validatedText(text); err != nil {
+		return nil, err
+	}
+
+	entities, err := s.resolvedEntities(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.db.CreatePost(ctx, authorID, text, parentID, quoteOfID, entities)
+	if err != nil {
+		log.Printf("error creating post: %+v", err)
+		var reference *repository.ReferenceError
+		if errors.As(err, &reference) {
+			switch reference.Constraint {
+			case parentConstraint:
+				return nil, ErrParentNotFound
+			case quoteConstraint:
+				return nil, ErrQuotedNotFound
+			case authorConstraint:
+				// the author's account was purged since their token was issued
+				return nil, ErrAuthorNotFound
+			}
+		}
+		return nil, err
+	}
+
+	if s.timelines != nil {
+		// the post is already saved, so the fan-out should not be cut short by the
+		// client going away and a failure is logged rather than failing the request
+		if err := s.timelines.Publish(context.WithoutCancel(ctx), *post); err != nil {
+			log.Printf("error publishing post to timelines: %+v", err)
+		}
+	}
+
+	if err := s.addOriginals(ctx, authorID, []*model.Post{post}); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// resolvedEntities returns the hashtags and mentions in text, with mentions
+// resolved to the users they refer to. Mentions of handles nobody holds are
+// left out, so they are not linked.
+func (s *service) resolvedEntities(ctx context.Context, text string) ([]model.Entity, error) {
+	entities := entity.Extract(text)
+
+	var handles []string
+	for _, e := range entities {
+		if e.Type == model.EntityMention {
+			handles = append(handles, e.Text)
+		}
+	}
+
+	if len(handles) == 0 {
+		return entities, nil
+	}
+
+	ids, err := s.db.GetUserIDsByHandles(ctx, handles)
+	if err != nil {
+		log.Printf("error resolving mentions: %+v", err)
+		return nil, err
+	}
+
+	resolved := entities[:0]
+	for _, e := range entities {
+		if e.Type == model.EntityMention {
+			id, ok := ids[e.Text]
+			if !ok {
+				continue
+			}
+			e.UserID = &id
+		}
+		resolved = append(resolved, e)
+	}
+
+	return resolved, nil
+}
+
+// GetPost returns the post with the given id. viewerID is 0 for anonymous
+// readers, who are not told whether they like it.
+func (s *service) GetPost(ctx context.Context, id, viewerID int) (*model.Post, error) {
+	post, err := s.db.GetPost(ctx, id)
+	if err != nil {
+		log.Printf("error fetching post: %+v", err)
+		return nil, domainError(err)
+	}
+
+	if err := s.Decorate(ctx, viewerID, []*model.Post{post}); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+func (s *service) DeletePost(ctx context.Context, id, userID int) error {
+	post, err := s.db.GetPost(ctx, id)
+	if err != nil {
+		log.Printf("error fetching post: %+v", err)
+		return domainError(err)
+	}
+
+	if post.AuthorID != userID {
+		return ErrNotAuthor
+	}
+
+	if err := s.db.DeletePost(ctx, id); err != nil {
+		log.Printf("error deleting post: %+v", err)
+		return domainError(err)
+	}
+
+	if s.timelines != nil {
+		if err := s.timelines.Retract(ctx, id); err != nil {
+			log.Printf("error retracting post from timelines: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) GetTimeline(ctx context.Context, userID int, cursor string, limit int) (*model.Page[model.Post], error) {
+	after, err := model.ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = model.PageLimit(limit)
+
+	fetch := func(limit int) ([]model.Post, error) {
+		if s.timelines != nil {
+			return s.timelines.GetTimeline(ctx, userID, after, limit)
+		}
+		return s.db.GetTimeline(ctx, userID, after, limit)
+	}
+
+	page, err := model.FetchPage(limit, fetch, func(last model.Post) string {
+		return model.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.String()
+	})
+	if err != nil {
+		log.Printf("error fetching timeline: %+v", err)
+		return nil, err
+	}
+
+	if err := s.Decorate(ctx, userID, model.PostPointers(page.Items)); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// Decorate adds what is read along with posts: whether viewerID likes them,
+// unless viewerID is 0, and the posts they repost or quote. It makes the same
+// few queries however many posts there are.
+func (s *service) Decorate(ctx context.Context, viewerID int, posts []*model.Post) error {
+	if viewerID != 0 {
+		if err := s.addLikes(ctx, viewerID, posts); err != nil {
+			return err
+		}
+	}
+
+	return s.addOriginals(ctx, viewerID, posts)
+}
+
+// addLikes sets the like count of posts and whether viewerID likes them with a
+// single query for the whole list. The like and repost counts are refreshed too
+// since posts served from a timeline store are copies taken when they were
+// published.
+func (s *service) addLikes(ctx context.Context, viewerID int, posts []*model.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	likes, err := s.db.GetPostLikes(ctx, viewerID, ids)
+	if err != nil {
+		log.Printf("error fetching post likes: %+v", err)
+		return err
+	}
+
+	byPost := make(map[int]model.PostLikes, len(likes))
+	for _, like := range likes {
+		byPost[like.PostID] = like
+	}
+
+	for _, post := range posts {
+		like := byPost[post.ID]
+		post.LikeCount = like.LikeCount
+		post.RepostCount = like.RepostCount
+		post.Liked = &like.Liked
+	}
+
+	return nil
+}
+
+// addOriginals sets the Original of reposts and quotes among posts, along with
+// whether viewerID likes it unless viewerID is 0. An original that was deleted
+// since is left out.
+func (s *service) addOriginals(ctx context.Context, viewerID int, posts []*model.Post) error {
+	var ids []int
+	for _, post := range posts {
+		if id := originalID(post); id != nil {
+			ids = append(ids, *id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	originals, err := s.db.GetPosts(ctx, ids)
+	if err != nil {
+		log.Printf("error fetching original posts: %+v", err)
+		return err
+	}
+
+	if viewerID != 0 {
+		if err := s.addLikes(ctx, viewerID, model.PostPointers(originals)); err != nil {
+			return err
+		}
+	}
+
+	byID := make(map[int]*model.Post, len(originals))
+	for i := range originals {
+		byID[originals[i].ID] = &originals[i]
+	}
+
+	for _, post := range posts {
+		if id := originalID(post); id != nil {
+			post.Original = byID[*id]
+		}
+	}
+
+	return nil
+}
+
+func originalID(post *model.Post) *int {
+	if post.RepostOfID != nil {
+		return post.RepostOfID
+	}
+
+	return post.QuoteOfID
+}
+
+// domainError translates repository errors into the errors of this package.
+func domainError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrPostNotFound
+	}
+
+	return err
+}
+
+func validatedText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return ErrEmptyPost
+	}
+
+	if utf8.RuneCountInString(text) > MaxPostLength {
+		return ErrPostTooLong
+	}
+
+	return nil
+}
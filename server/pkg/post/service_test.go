@@ -0,0 +1,466 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/repository"
+	"x/pkg/util"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRepo struct {
+	mock.Mock
+}
+
+func (m *mockRepo) CreatePost(ctx context.Context, authorID int, text string, parentID, quoteOfID *int, entities []model.Entity) (*model.Post, error) {
+	args := m.Called(authorID, text, parentID, quoteOfID, entities)
+
+	return args.Get(0).(*model.Post), args.Error(1)
+}
+
+func (m *mockRepo) GetUserIDsByHandles(ctx context.Context, handles []string) (map[string]int, error) {
+	args := m.Called(handles)
+
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *mockRepo) GetPost(ctx context.Context, id int) (*model.Post, error) {
+	args := m.Called(id)
+
+	return args.Get(0).(*model.Post), args.Error(1)
+}
+
+func (m *mockRepo) GetPosts(ctx context.Context, ids []int) ([]model.Post, error) {
+	args := m.Called(ids)
+
+	return args.Get(0).([]model.Post), args.Error(1)
+}
+
+func (m *mockRepo) Repost(ctx context.Context, userID, postID int) (*model.Post, error) {
+	args := m.Called(userID, postID)
+
+	return args.Get(0).(*model.Post), args.Error(1)
+}
+
+func (m *mockRepo) DeleteRepost(ctx context.Context, userID, postID int) (int, error) {
+	args := m.Called(userID, postID)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockRepo) DeletePost(ctx context.Context, id int) error {
+	args := m.Called(id)
+
+	return args.Error(0)
+}
+
+func (m *mockRepo) GetTimeline(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error) {
+	args := m.Called(userID, after, limit)
+
+	return args.Get(0).([]model.Post), args.Error(1)
+}
+
+func (m *mockRepo) GetPopularTimeline(ctx context.Context, userID, minFollowers int, after *model.Cursor, limit int) ([]model.Post, error) {
+	args := m.Called(userID, minFollowers, after, limit)
+
+	return args.Get(0).([]model.Post), args.Error(1)
+}
+
+func (m *mockRepo) GetAuthorPosts(ctx context.Context, authorID, limit int) ([]model.Post, error) {
+	args := m.Called(authorID, limit)
+
+	return args.Get(0).([]model.Post), args.Error(1)
+}
+
+func (m *mockRepo) GetPostLikes(ctx context.Context, userID int, postIDs []int) ([]model.PostLikes, error) {
+	args := m.Called(userID, postIDs)
+
+	return args.Get(0).([]model.PostLikes), args.Error(1)
+}
+
+func (m *mockRepo) GetAncestors(ctx context.Context, id int) ([]model.Post, error) {
+	args := m.Called(id)
+
+	return args.Get(0).([]model.Post), args.Error(1)
+}
+
+func (m *mockRepo) GetDescendants(ctx context.Context, id int, after []int, limit int) ([]model.ThreadPost, error) {
+	args := m.Called(id, after, limit)
+
+	return args.Get(0).([]model.ThreadPost), args.Error(1)
+}
+
+type mockTimelines struct {
+	mock.Mock
+}
+
+func (m *mockTimelines) Publish(ctx context.Context, post model.Post) error {
+	args := m.Called(post)
+
+	return args.Error(0)
+}
+
+func (m *mockTimelines) Retract(ctx context.Context, postID int) error {
+	args := m.Called(postID)
+
+	return args.Error(0)
+}
+
+func (m *mockTimelines) GetTimeline(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error) {
+	args := m.Called(userID, after, limit)
+
+	return args.Get(0).([]model.Post), args.Error(1)
+}
+
+func (m *mockTimelines) Follow(ctx context.Context, followerID, followeeID int) error {
+	args := m.Called(followerID, followeeID)
+
+	return args.Error(0)
+}
+
+func (m *mockTimelines) Unfollow(ctx context.Context, followerID, followeeID int) error {
+	args := m.Called(followerID, followeeID)
+
+	return args.Error(0)
+}
+
+func TestCreatePost_ReturnsPost(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	expected := &model.Post{
+		ID: 1,
+		AuthorID: 2,
+		Text: "hello",
+		CreatedAt: time.Now(),
+	}
+
+	mockRepo.On("CreatePost", 2, "hello", (*int)(nil), (*int)(nil), []model.Entity{}).Return(expected, nil)
+
+	actual, err := service.CreatePost(context.Background(), 2, "hello", nil, nil)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual, expected, t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePost_EmptyText_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	_, actual := service.CreatePost(context.Background(), 2, "   ", nil, nil)
+	if !errors.Is(actual, ErrEmptyPost) {
+		t.Errorf("expected: %+v, actual: %+v", ErrEmptyPost, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreatePost_TooLong_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	_, actual := service.CreatePost(context.Background(), 2, strings.Repeat("a", MaxPostLength+1), nil, nil)
+	if !errors.Is(actual, ErrPostTooLong) {
+		t.Errorf("expected: %+v, actual: %+v", ErrPostTooLong, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreatePost_MultiByteAtLimit_ReturnsPost(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	text := strings.Repeat("é", MaxPostLength)
+	mockRepo.On("CreatePost", 2, text, (*int)(nil), (*int)(nil), []model.Entity{}).Return(&model.Post{ID: 1, AuthorID: 2, Text: text}, nil)
+
+	_, actual := service.CreatePost(context.Background(), 2, text, nil, nil)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePost_NoAuthor_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	mockRepo.On("CreatePost", 2, "hello", (*int)(nil), (*int)(nil), []model.Entity{}).Return((*model.Post)(nil), &repository.ReferenceError{Constraint: authorConstraint, Err: errors.New("test error")})
+
+	_, actual := service.CreatePost(context.Background(), 2, "hello", nil, nil)
+	if !errors.Is(actual, ErrAuthorNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrAuthorNotFound, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePost_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	expected := errors.New("test error")
+
+	mockRepo.On("CreatePost", 2, "hello", (*int)(nil), (*int)(nil), []model.Entity{}).Return((*model.Post)(nil), expected)
+
+	_, actual := service.CreatePost(context.Background(), 2, "hello", nil, nil)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePost_ResolvesMentions(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	text := "hi @Alice and @nobody #Go"
+	aliceID := 3
+	entities := []model.Entity{
+		{Type: model.EntityMention, Start: 3, End: 9, Text: "alice", UserID: &aliceID},
+		{Type: model.EntityHashtag, Start: 22, End: 25, Text: "go"},
+	}
+
+	mockRepo.On("GetUserIDsByHandles", []string{"alice", "nobody"}).Return(map[string]int{"alice": 3}, nil)
+	mockRepo.On("CreatePost", 2, text, (*int)(nil), (*int)(nil), entities).Return(&model.Post{ID: 1, AuthorID: 2, Text: text, Entities: entities}, nil)
+
+	actual, err := service.CreatePost(context.Background(), 2, text, nil, nil)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual.Entities, entities, t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetPost_ReturnsPost(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	expected := &model.Post{
+		ID: 1,
+		AuthorID: 2,
+		Text: "hello",
+		CreatedAt: time.Now(),
+	}
+
+	mockRepo.On("GetPost", 1).Return(expected, nil)
+
+	actual, _ := service.GetPost(context.Background(), 1, 0)
+	util.AssertJSON(actual, expected, t)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetPostLikes", mock.Anything, mock.Anything)
+}
+
+func TestGetPost_WithViewer_AddsLikes(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	liked := true
+	post := &model.Post{ID: 1, AuthorID: 2, Text: "hello", CreatedAt: time.Now()}
+	expected := &model.Post{ID: 1, AuthorID: 2, Text: "hello", CreatedAt: post.CreatedAt, LikeCount: 4, Liked: &liked}
+
+	mockRepo.On("GetPost", 1).Return(post, nil)
+	mockRepo.On("GetPostLikes", 3, []int{1}).Return([]model.PostLikes{{PostID: 1, LikeCount: 4, Liked: true}}, nil)
+
+	actual, err := service.GetPost(context.Background(), 1, 3)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual, expected, t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeletePost_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	expected := errors.New("test error")
+
+	mockRepo.On("GetPost", 1).Return(&model.Post{ID: 1, AuthorID: 2}, nil)
+	mockRepo.On("DeletePost", 1).Return(expected)
+
+	actual := service.DeletePost(context.Background(), 1, 2)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeletePost_NotAuthor_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	mockRepo.On("GetPost", 1).Return(&model.Post{ID: 1, AuthorID: 2}, nil)
+
+	actual := service.DeletePost(context.Background(), 1, 3)
+	if !errors.Is(actual, ErrNotAuthor) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotAuthor, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "DeletePost", mock.Anything)
+}
+
+func TestDeletePost_NotFound_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	mockRepo.On("GetPost", 1).Return((*model.Post)(nil), repository.ErrNotFound)
+
+	actual := service.DeletePost(context.Background(), 1, 2)
+	if !errors.Is(actual, ErrPostNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrPostNotFound, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "DeletePost", mock.Anything)
+}
+
+func TestGetTimeline_ReturnsPageWithNextCursor(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	dummyTime := time.Now().UTC().Truncate(time.Microsecond)
+	posts := []model.Post{
+		{ID: 3, AuthorID: 2, Text: "third", CreatedAt: dummyTime},
+		{ID: 2, AuthorID: 2, Text: "second", CreatedAt: dummyTime.Add(-time.Minute)},
+		{ID: 1, AuthorID: 2, Text: "first", CreatedAt: dummyTime.Add(-2 * time.Minute)},
+	}
+
+	mockRepo.On("GetTimeline", 1, (*model.Cursor)(nil), 3).Return(posts, nil)
+	mockRepo.On("GetPostLikes", 1, []int{3, 2}).Return([]model.PostLikes{}, nil)
+
+	actual, err := service.GetTimeline(context.Background(), 1, "", 2)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual.Items, posts[:2], t)
+
+	next, err := model.ParseCursor(actual.NextCursor)
+	if err != nil {
+		t.Fatalf("expected a valid next cursor, actual: %+v", err)
+	}
+
+	if next.ID != 2 || !next.CreatedAt.Equal(posts[1].CreatedAt) {
+		t.Errorf("expected cursor at post 2, actual: %+v", next)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTimeline_LastPage_ReturnsNoNextCursor(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	after := model.Cursor{CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: 5}
+	posts := []model.Post{{ID: 4, AuthorID: 2, Text: "fourth", CreatedAt: after.CreatedAt}}
+
+	mockRepo.On("GetTimeline", 1, &after, model.DefaultPageLimit+1).Return(posts, nil)
+	mockRepo.On("GetPostLikes", 1, []int{4}).Return([]model.PostLikes{}, nil)
+
+	actual, err := service.GetTimeline(context.Background(), 1, after.String(), 0)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual.Items, posts, t)
+	if actual.NextCursor != "" {
+		t.Errorf("expected no next cursor, actual: %+v", actual.NextCursor)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTimeline_InvalidCursor_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	_, actual := service.GetTimeline(context.Background(), 1, "not a cursor", 10)
+	if !errors.Is(actual, model.ErrInvalidCursor) {
+		t.Errorf("expected: %+v, actual: %+v", model.ErrInvalidCursor, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetTimeline", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreatePost_WithTimelines_PublishesPost(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockTimelines := &mockTimelines{}
+	service := New(mockRepo, mockTimelines)
+
+	expected := &model.Post{ID: 1, AuthorID: 2, Text: "hello", CreatedAt: time.Now()}
+
+	mockRepo.On("CreatePost", 2, "hello", (*int)(nil), (*int)(nil), []model.Entity{}).Return(expected, nil)
+	mockTimelines.On("Publish", *expected).Return(errors.New("test error"))
+
+	actual, err := service.CreatePost(context.Background(), 2, "hello", nil, nil)
+	if err != nil {
+		t.Errorf("expected a failed fan-out not to fail the request, actual: %+v", err)
+	}
+
+	util.AssertJSON(actual, expected, t)
+	mockRepo.AssertExpectations(t)
+	mockTimelines.AssertExpectations(t)
+}
+
+func TestGetTimeline_WithTimelines_ReadsFromTimelines(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockTimelines := &mockTimelines{}
+	service := New(mockRepo, mockTimelines)
+
+	posts := []model.Post{{ID: 1, AuthorID: 2, Text: "hello", CreatedAt: time.Now()}}
+
+	mockTimelines.On("GetTimeline", 1, (*model.Cursor)(nil), 11).Return(posts, nil)
+	mockRepo.On("GetPostLikes", 1, []int{1}).Return([]model.PostLikes{}, nil)
+
+	actual, err := service.GetTimeline(context.Background(), 1, "", 10)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual.Items, posts, t)
+	mockRepo.AssertNotCalled(t, "GetTimeline", mock.Anything, mock.Anything, mock.Anything)
+	mockTimelines.AssertExpectations(t)
+}
+
+func TestGetTimeline_RefreshesLikes(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockTimelines := &mockTimelines{}
+	service := New(mockRepo, mockTimelines)
+
+	dummyTime := time.Now()
+	posts := []model.Post{
+		{ID: 2, AuthorID: 2, Text: "second", CreatedAt: dummyTime},
+		{ID: 1, AuthorID: 2, Text: "first", CreatedAt: dummyTime.Add(-time.Minute), LikeCount: 1, RepostCount: 1},
+	}
+
+	mockTimelines.On("GetTimeline", 1, (*model.Cursor)(nil), 11).Return(posts, nil)
+	mockRepo.On("GetPostLikes", 1, []int{2, 1}).Return([]model.PostLikes{{PostID: 1, LikeCount: 7, RepostCount: 3, Liked: true}}, nil)
+
+	actual, err := service.GetTimeline(context.Background(), 1, "", 10)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	liked, notLiked := true, false
+	expected := []model.Post{
+		{ID: 2, AuthorID: 2, Text: "second", CreatedAt: dummyTime, LikeCount: 0, Liked: &notLiked},
+		{ID: 1, AuthorID: 2, Text: "first", CreatedAt: dummyTime.Add(-time.Minute), LikeCount: 7, RepostCount: 3, Liked: &liked},
+	}
+
+	util.AssertJSON(actual.Items, expected, t)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,92 @@
+package post
+
+import (
+	"context"
+	"log"
+	"slices"
+	"x/pkg/model"
+)
+
+// GetThread returns the post with the given id, the posts it replies to and a
+// page of the replies below it, nested as a tree. Replies are paged depth
+// first, so a page may start with replies whose parent was on an earlier page;
+// those are at the top level of the page and their ParentID tells where they
+// belong. viewerID is 0 for anonymous readers.
+func (s *service) GetThread(ctx context.Context, id, viewerID int, cursor string, limit int) (*model.Thread, error) {
+	after, err := model.ParseThreadCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = model.PageLimit(limit)
+
+	post, err := s.db.GetPost(ctx, id)
+	if err != nil {
+		log.Printf("error fetching post: %+v", err)
+		return nil, domainError(err)
+	}
+
+	ancestors, err := s.db.GetAncestors(ctx, id)
+	if err != nil {
+		log.Printf("error fetching ancestors: %+v", err)
+		return nil, err
+	}
+
+	var afterPath []int
+	if after != nil {
+		afterPath = after.Path
+	}
+
+	replies, err := model.FetchPage(limit, func(limit int) ([]model.ThreadPost, error) {
+		return s.db.GetDescendants(ctx, id, afterPath, limit)
+	}, func(last model.ThreadPost) string {
+		return model.ThreadCursor{Path: last.Path}.String()
+	})
+	if err != nil {
+		log.Printf("error fetching replies: %+v", err)
+		return nil, err
+	}
+
+	thread := &model.Thread{Ancestors: ancestors, Post: *post}
+	thread.Replies.NextCursor = replies.NextCursor
+
+	posts := append(model.PostPointers(thread.Ancestors), &thread.Post)
+	for i := range replies.Items {
+		posts = append(posts, &replies.Items[i].Post)
+	}
+
+	if err := s.Decorate(ctx, viewerID, posts); err != nil {
+		return nil, err
+	}
+
+	thread.Replies.Items = nest(replies.Items)
+
+	return thread, nil
+}
+
+// nest turns a depth-first list of replies into trees. Replies whose parent is
+// not in the list become roots.
+func nest(replies []model.ThreadPost) []model.ThreadNode {
+	nodes := []model.ThreadNode{}
+	for len(replies) > 0 {
+		var siblings []model.ThreadNode
+		first := replies[0].Path
+		siblings, replies = children(replies, first[:len(first)-1])
+		nodes = append(nodes, siblings...)
+	}
+
+	return nodes
+}
+
+// children takes the replies at the start of the list that descend from the
+// reply at parent and returns them as trees, along with the remaining replies.
+func children(replies []model.ThreadPost, parent []int) ([]model.ThreadNode, []model.ThreadPost) {
+	nodes := []model.ThreadNode{}
+	for len(replies) > 0 && len(replies[0].Path) > len(parent) && slices.Equal(replies[0].Path[:len(parent)], parent) {
+		node := model.ThreadNode{Post: replies[0].Post}
+		node.Replies, replies = children(replies[1:], replies[0].Path)
+		nodes = append(nodes, node)
+	}
+
+	return nodes, replies
+}
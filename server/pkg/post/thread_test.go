@@ -0,0 +1,173 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/repository"
+	"x/pkg/util"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func reply(id, parentID int, path ...int) model.ThreadPost {
+	return model.ThreadPost{Post: model.Post{ID: id, AuthorID: 2, ParentID: &parentID}, Path: path}
+}
+
+func TestCreatePost_NoParent_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	parentID := 9
+	mockRepo.On("CreatePost", 2, "hello", &parentID, (*int)(nil), []model.Entity{}).Return((*model.Post)(nil), &repository.ReferenceError{Constraint: parentConstraint, Err: errors.New("test error")})
+
+	_, actual := service.CreatePost(context.Background(), 2, "hello", &parentID, nil)
+	if !errors.Is(actual, ErrParentNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrParentNotFound, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreatePost_QuotingReplyNoParent_ReturnsParentError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	parentID, quoteOfID := 9, 1
+	mockRepo.On("CreatePost", 2, "hello", &parentID, &quoteOfID, []model.Entity{}).Return((*model.Post)(nil), &repository.ReferenceError{Constraint: parentConstraint, Err: errors.New("test error")})
+
+	_, actual := service.CreatePost(context.Background(), 2, "hello", &parentID, &quoteOfID)
+	if !errors.Is(actual, ErrParentNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrParentNotFound, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetThread_NestsReplies(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	post := &model.Post{ID: 1, AuthorID: 2, Text: "hello", CreatedAt: time.Now()}
+	replies := []model.ThreadPost{
+		reply(2, 1, 2),
+		reply(4, 2, 2, 4),
+		reply(6, 4, 2, 4, 6),
+		reply(3, 1, 3),
+		reply(5, 1, 5),
+	}
+
+	mockRepo.On("GetPost", 1).Return(post, nil)
+	mockRepo.On("GetAncestors", 1).Return([]model.Post{}, nil)
+	mockRepo.On("GetDescendants", 1, []int(nil), 5).Return(replies, nil)
+
+	actual, err := service.GetThread(context.Background(), 1, 0, "", 4)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	expected := []model.ThreadNode{
+		{Post: replies[0].Post, Replies: []model.ThreadNode{
+			{Post: replies[1].Post, Replies: []model.ThreadNode{
+				{Post: replies[2].Post, Replies: []model.ThreadNode{}},
+			}},
+		}},
+		{Post: replies[3].Post, Replies: []model.ThreadNode{}},
+	}
+
+	util.AssertJSON(actual.Replies.Items, expected, t)
+
+	next, err := model.ParseThreadCursor(actual.Replies.NextCursor)
+	if err != nil || next == nil || len(next.Path) != 1 || next.Path[0] != 3 {
+		t.Errorf("expected cursor at reply 3, actual: %+v, error: %+v", next, err)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetPostLikes", mock.Anything, mock.Anything)
+}
+
+func TestGetThread_PageStartingDeep_ReturnsOrphansAtTopLevel(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	post := &model.Post{ID: 1, AuthorID: 2, Text: "hello", CreatedAt: time.Now()}
+	replies := []model.ThreadPost{
+		reply(6, 4, 2, 4, 6),
+		reply(7, 2, 2, 7),
+		reply(3, 1, 3),
+	}
+	after := model.ThreadCursor{Path: []int{2, 4}}
+
+	mockRepo.On("GetPost", 1).Return(post, nil)
+	mockRepo.On("GetAncestors", 1).Return([]model.Post{}, nil)
+	mockRepo.On("GetDescendants", 1, []int{2, 4}, model.DefaultPageLimit+1).Return(replies, nil)
+
+	actual, err := service.GetThread(context.Background(), 1, 0, after.String(), 0)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	expected := []model.ThreadNode{
+		{Post: replies[0].Post, Replies: []model.ThreadNode{}},
+		{Post: replies[1].Post, Replies: []model.ThreadNode{}},
+		{Post: replies[2].Post, Replies: []model.ThreadNode{}},
+	}
+
+	util.AssertJSON(actual.Replies.Items, expected, t)
+	if actual.Replies.NextCursor != "" {
+		t.Errorf("expected no next cursor, actual: %+v", actual.Replies.NextCursor)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetThread_WithViewer_AddsLikes(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	rootID := 1
+	ancestors := []model.Post{{ID: 1, AuthorID: 2, Text: "root"}}
+	post := &model.Post{ID: 2, AuthorID: 2, Text: "hello", ParentID: &rootID, RootID: &rootID}
+	replies := []model.ThreadPost{reply(3, 2, 3)}
+
+	mockRepo.On("GetPost", 2).Return(post, nil)
+	mockRepo.On("GetAncestors", 2).Return(ancestors, nil)
+	mockRepo.On("GetDescendants", 2, []int(nil), model.DefaultPageLimit+1).Return(replies, nil)
+	mockRepo.On("GetPostLikes", 7, []int{1, 2, 3}).Return([]model.PostLikes{
+		{PostID: 1, LikeCount: 1, Liked: true},
+		{PostID: 3, LikeCount: 2, Liked: false},
+	}, nil)
+
+	actual, err := service.GetThread(context.Background(), 2, 7, "", 0)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if actual.Ancestors[0].LikeCount != 1 || !*actual.Ancestors[0].Liked {
+		t.Errorf("expected the ancestor liked once by the viewer, actual: %+v", actual.Ancestors[0])
+	}
+	if actual.Post.LikeCount != 0 || *actual.Post.Liked {
+		t.Errorf("expected the post not liked, actual: %+v", actual.Post)
+	}
+	if reply := actual.Replies.Items[0]; reply.LikeCount != 2 || *reply.Liked {
+		t.Errorf("expected the reply liked twice but not by the viewer, actual: %+v", reply)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetThread_NotFound_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, nil)
+
+	mockRepo.On("GetPost", 1).Return((*model.Post)(nil), repository.ErrNotFound)
+
+	_, actual := service.GetThread(context.Background(), 1, 0, "", 0)
+	if !errors.Is(actual, ErrPostNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrPostNotFound, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetDescendants", mock.Anything, mock.Anything, mock.Anything)
+}
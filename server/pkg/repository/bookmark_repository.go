@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+	"x/pkg/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BookmarkRepository stores the posts each user saved for later. Bookmarks are
+// only ever read by the user who made them.
+type BookmarkRepository interface {
+	Bookmark(ctx context.Context, userID, postID int) error
+	Unbookmark(ctx context.Context, userID, postID int) error
+	GetBookmarks(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.BookmarkedPost, error)
+}
+
+// Bookmark saves postID for userID. Bookmarking a post twice is not an error.
+func (r *repository) Bookmark(ctx context.Context, userID, postID int) error {
+	_, err := r.db.Exec(ctx, "insert into bookmarks (user_id, post_id) values ($1, $2) on conflict do nothing", userID, postID)
+	if err != nil {
+		log.Printf("error inserting bookmark: %+v", err)
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// Unbookmark removes userID's bookmark of postID, if there is one.
+func (r *repository) Unbookmark(ctx context.Context, userID, postID int) error {
+	_, err := r.db.Exec(ctx, "delete from bookmarks where user_id = $1 and post_id = $2", userID, postID)
+	if err != nil {
+		log.Printf("error deleting bookmark: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetBookmarks returns up to limit posts bookmarked by userID, most recently
+// bookmarked first, starting strictly after the given cursor. The cursor is
+// positioned by bookmark time and post id.
+func (r *repository) GetBookmarks(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.BookmarkedPost, error) {
+	var createdAt *time.Time
+	var id *int
+	if after != nil {
+		createdAt = &after.CreatedAt
+		id = &after.ID
+	}
+
+	rows, err := r.db.Query(ctx, `select p.id, p.author_id, p.text, p.created_at, p.like_count, p.parent_id, p.root_id, p.kind, p.repost_of_id, p.quote_of_id, p.repost_count, p.entities, b.created_at as bookmarked_at
+		from bookmarks b
+		join posts p on p.id = b.post_id
+		where b.user_id = $1
+		and ($2::timestamptz is null or (b.created_at, b.post_id) < ($2::timestamptz, $3::int))
+		order by b.created_at desc, b.post_id desc
+		limit $4`, userID, createdAt, id, limit)
+	if err != nil {
+		log.Printf("error querying bookmarks: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.BookmarkedPost])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
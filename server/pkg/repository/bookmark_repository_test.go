@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/util"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestBookmark_IgnoresDuplicates(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("insert into bookmarks (.+) on conflict do nothing").WithArgs(1, 2).WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	// act
+	err = repo.Bookmark(context.Background(), 1, 2)
+
+	// assert
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBookmarkNoPost_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("insert into bookmarks").WithArgs(1, 2).WillReturnError(&pgconn.PgError{Code: foreignKeyViolation})
+
+	// act
+	err = repo.Bookmark(context.Background(), 1, 2)
+
+	// assert
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUnbookmark_ReturnsNoError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("delete from bookmarks where user_id = \\$1 and post_id = \\$2").WithArgs(1, 2).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	// act
+	err = repo.Unbookmark(context.Background(), 1, 2)
+
+	// assert
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetBookmarks_ReturnsPosts(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := []model.BookmarkedPost{
+		{Post: model.Post{ID: 2, AuthorID: 3, Text: "hello", CreatedAt: dummyTime, Kind: model.PostKindPost}, BookmarkedAt: dummyTime.Add(time.Hour)},
+	}
+
+	after := model.Cursor{CreatedAt: dummyTime.Add(2 * time.Hour), ID: 5}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities", "bookmarked_at"}).
+		AddRow(2, 3, "hello", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil), dummyTime.Add(time.Hour))
+
+	mockDb.ExpectQuery("select (.+) from bookmarks b\\s+join posts p on p.id = b.post_id\\s+where b.user_id = \\$1").WithArgs(1, &after.CreatedAt, &after.ID, 11).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetBookmarks(context.Background(), 1, &after, 11)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
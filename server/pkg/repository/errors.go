@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	foreignKeyViolation  = "23503"
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+var (
+	// ErrNotFound is returned when a row being read, updated or deleted does not
+	// exist, or when a row being written references one that does not. In the
+	// latter case the returned error is a *ReferenceError naming the foreign key.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write would violate a unique constraint.
+	// The returned error is a *ConflictError naming the constraint.
+	ErrConflict = errors.New("already exists")
+)
+
+// ConflictError reports which unique constraint a write violated.
+type ConflictError struct {
+	Constraint string
+	Err error
+}
+
+func (e *ConflictError) Error() string {
+	return "conflict on " + e.Constraint + ": " + e.Err.Error()
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ReferenceError reports which foreign key a write violated.
+type ReferenceError struct {
+	Constraint string
+	Err error
+}
+
+func (e *ReferenceError) Error() string {
+	return "missing reference of " + e.Constraint + ": " + e.Err.Error()
+}
+
+func (e *ReferenceError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// translateError turns pgx and postgres errors into the errors above, leaving
+// any other error as it is.
+func translateError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return &ConflictError{Constraint: pgErr.ConstraintName, Err: err}
+		case foreignKeyViolation:
+			return &ReferenceError{Constraint: pgErr.ConstraintName, Err: err}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"x/pkg/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type FollowRepository interface {
+	Follow(ctx context.Context, followerID, followeeID int) (bool, error)
+	Unfollow(ctx context.Context, followerID, followeeID int) (bool, error)
+	GetFollowers(ctx context.Context, id int) ([]model.User, error)
+	GetFollowing(ctx context.Context, id int) ([]model.User, error)
+	GetFollowerIDs(ctx context.Context, id int) ([]int, error)
+	CountFollowers(ctx context.Context, id int) (int, error)
+}
+
+// Follow records that followerID follows followeeID. It reports false when the
+// follow already existed, and ErrNotFound when followeeID does not exist or
+// deleted their account.
+func (r *repository) Follow(ctx context.Context, followerID, followeeID int) (bool, error) {
+	rows, err := r.db.Query(ctx, `with followee as (
+			select id from users where id = $2 and deleted_at is null
+		), created as (
+			insert into follows (follower_id, followee_id) select $1, id from followee
+			on conflict do nothing
+			returning 1
+		)
+		select exists (select 1 from followee), exists (select 1 from created)`, followerID, followeeID)
+	if err != nil {
+		log.Printf("error inserting follow: %+v", err)
+		return false, translateError(err)
+	}
+
+	defer rows.Close()
+
+	var found, created bool
+	_, err = pgx.ForEachRow(rows, []any{&found, &created}, func() error { return nil })
+	if err != nil {
+		log.Printf("error inserting follow: %+v", err)
+		return false, translateError(err)
+	}
+
+	if !found {
+		return false, ErrNotFound
+	}
+
+	return created, nil
+}
+
+// Unfollow removes the follow from followerID to followeeID. It reports false
+// when there was nothing to remove.
+func (r *repository) Unfollow(ctx context.Context, followerID, followeeID int) (bool, error) {
+	tag, err := r.db.Exec(ctx, "delete from follows where follower_id = $1 and followee_id = $2", followerID, followeeID)
+	if err != nil {
+		log.Printf("error deleting follow: %+v", err)
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *repository) GetFollowers(ctx context.Context, id int) ([]model.User, error) {
+	rows, err := r.db.Query(ctx, "select u.id, u.name, u.handle, u.email, u.upserted_at, u.bio, u.dob from follows f join users u on u.id = f.follower_id where f.followee_id = $1 and u.deleted_at is null order by f.created_at desc", id)
+	if err != nil {
+		log.Printf("error querying followers: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.User])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *repository) GetFollowing(ctx context.Context, id int) ([]model.User, error) {
+	rows, err := r.db.Query(ctx, "select u.id, u.name, u.handle, u.email, u.upserted_at, u.bio, u.dob from follows f join users u on u.id = f.followee_id where f.follower_id = $1 and u.deleted_at is null order by f.created_at desc", id)
+	if err != nil {
+		log.Printf("error querying following: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.User])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *repository) GetFollowerIDs(ctx context.Context, id int) ([]int, error) {
+	rows, err := r.db.Query(ctx, "select follower_id from follows where followee_id = $1", id)
+	if err != nil {
+		log.Printf("error querying follower ids: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CountFollowers reads the follower count kept up to date on follow and
+// unfollow, rather than counting follows.
+func (r *repository) CountFollowers(ctx context.Context, id int) (int, error) {
+	rows, err := r.db.Query(ctx, "select follower_count from users where id = $1", id)
+	if err != nil {
+		log.Printf("error counting followers: %+v", err)
+		return 0, err
+	}
+
+	count, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[int])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return 0, translateError(err)
+	}
+
+	return count, nil
+}
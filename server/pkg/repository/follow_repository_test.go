@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/util"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestFollow_ReturnsCreated(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery("insert into follows").WithArgs(1, 2).WillReturnRows(mockDb.NewRows([]string{"exists", "exists"}).AddRow(true, true))
+
+	// act
+	actual, err := repo.Follow(context.Background(), 1, 2)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	// assert
+	if !actual {
+		t.Errorf("expected follow to be created")
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFollowDuplicate_ReturnsNotCreated(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery("insert into follows").WithArgs(1, 2).WillReturnRows(mockDb.NewRows([]string{"exists", "exists"}).AddRow(true, false))
+
+	// act
+	actual, err := repo.Follow(context.Background(), 1, 2)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	// assert
+	if actual {
+		t.Errorf("expected follow not to be created")
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFollow_ReturnsError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	expected := errors.New("test error")
+
+	mockDb.ExpectQuery("insert into follows").WithArgs(1, 2).WillReturnError(expected)
+
+	// act
+	_, actual := repo.Follow(context.Background(), 1, 2)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFollowDeactivatedUser_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery(`select id from users where id = \$2 and deleted_at is null`).WithArgs(1, 2).WillReturnRows(mockDb.NewRows([]string{"exists", "exists"}).AddRow(false, false))
+
+	// act
+	_, actual := repo.Follow(context.Background(), 1, 2)
+	if !errors.Is(actual, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUnfollow_ReturnsRemoved(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("delete from follows").WithArgs(1, 2).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	// act
+	actual, err := repo.Unfollow(context.Background(), 1, 2)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	// assert
+	if !actual {
+		t.Errorf("expected follow to be removed")
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetFollowers_ReturnsUsers(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := []model.User{
+		{
+			ID: 1,
+			Name: "user 1",
+			Handle: "user1",
+			Email: "email1",
+			UpsertedAt: dummyTime,
+			Bio: "bio1",
+		},
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(1, "user 1", "user1", "email1", dummyTime, "bio1", (*model.Date)(nil))
+
+	mockDb.ExpectQuery("select (.+) from follows f join users u on u.id = f.follower_id").WithArgs(2).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetFollowers(context.Background(), 2)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetFollowing_ReturnsUsers(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := []model.User{
+		{
+			ID: 2,
+			Name: "user 2",
+			Handle: "user2",
+			Email: "email2",
+			UpsertedAt: dummyTime,
+			Bio: "bio2",
+		},
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(2, "user 2", "user2", "email2", dummyTime, "bio2", (*model.Date)(nil))
+
+	mockDb.ExpectQuery("select (.+) from follows f join users u on u.id = f.followee_id").WithArgs(1).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetFollowing(context.Background(), 1)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetFollowersFails_ReturnsError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery("select (.+) from follows").WithArgs(2).WillReturnError(errors.New("test error"))
+
+	// act
+	_, err = repo.GetFollowers(context.Background(), 2)
+
+	// assert
+	if err == nil || err.Error() != "test error" {
+		t.Errorf("expected: %+v, actual: %+v", "test error", err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCountFollowers_ReturnsCount(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockRows := mockDb.NewRows([]string{"follower_count"}).AddRow(3)
+
+	mockDb.ExpectQuery(`select follower_count from users where id = \$1`).WithArgs(2).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.CountFollowers(context.Background(), 2)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	// assert
+	if actual != 3 {
+		t.Errorf("expected: %+v, actual: %+v", 3, actual)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetFollowerIDs_ReturnsIDs(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockRows := mockDb.NewRows([]string{"follower_id"}).AddRow(1).AddRow(3)
+
+	mockDb.ExpectQuery("select follower_id from follows").WithArgs(2).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetFollowerIDs(context.Background(), 2)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, []int{1, 3}, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFollowMissingUser_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery("insert into follows").WithArgs(1, 2).WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: "follows_followee_id_fkey"})
+
+	// act
+	_, actual := repo.Follow(context.Background(), 1, 2)
+
+	// assert
+	if !errors.Is(actual, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, actual)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"log"
+)
+
+type LikeRepository interface {
+	Like(ctx context.Context, userID, postID int) error
+	Unlike(ctx context.Context, userID, postID int) error
+}
+
+// Like records that userID likes postID. Liking a post twice is not an error.
+func (r *repository) Like(ctx context.Context, userID, postID int) error {
+	_, err := r.db.Exec(ctx, "insert into likes (post_id, user_id) values ($1, $2) on conflict do nothing", postID, userID)
+	if err != nil {
+		log.Printf("error inserting like: %+v", err)
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// Unlike removes userID's like from postID, if there is one.
+func (r *repository) Unlike(ctx context.Context, userID, postID int) error {
+	_, err := r.db.Exec(ctx, "delete from likes where post_id = $1 and user_id = $2", postID, userID)
+	if err != nil {
+		log.Printf("error deleting like: %+v", err)
+		return err
+	}
+
+	return nil
+}
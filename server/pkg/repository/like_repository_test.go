@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestLike_IgnoresDuplicates(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("insert into likes (.+) on conflict do nothing").WithArgs(2, 1).WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	// act
+	err = repo.Like(context.Background(), 1, 2)
+
+	// assert
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLikeNoPost_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("insert into likes").WithArgs(2, 1).WillReturnError(&pgconn.PgError{Code: foreignKeyViolation})
+
+	// act
+	err = repo.Like(context.Background(), 1, 2)
+
+	// assert
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUnlike_ReturnsNoError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("delete from likes").WithArgs(2, 1).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	// act
+	err = repo.Unlike(context.Background(), 1, 2)
+
+	// assert
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,356 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"x/pkg/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostRepository interface {
+	CreatePost(ctx context.Context, authorID int, text string, parentID, quoteOfID *int, entities []model.Entity) (*model.Post, error)
+	GetUserIDsByHandles(ctx context.Context, handles []string) (map[string]int, error)
+	GetPost(ctx context.Context, id int) (*model.Post, error)
+	GetPosts(ctx context.Context, ids []int) ([]model.Post, error)
+	Repost(ctx context.Context, userID, postID int) (*model.Post, error)
+	DeleteRepost(ctx context.Context, userID, postID int) (int, error)
+	DeletePost(ctx context.Context, id int) error
+	GetTimeline(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error)
+	GetPopularTimeline(ctx context.Context, userID, minFollowers int, after *model.Cursor, limit int) ([]model.Post, error)
+	GetAuthorPosts(ctx context.Context, authorID, limit int) ([]model.Post, error)
+	GetPostLikes(ctx context.Context, userID int, postIDs []int) ([]model.PostLikes, error)
+	GetAncestors(ctx context.Context, id int) ([]model.Post, error)
+	GetDescendants(ctx context.Context, id int, after []int, limit int) ([]model.ThreadPost, error)
+}
+
+// CreatePost saves a post, as a reply to parentID and as a quote of quoteOfID
+// when they are not nil. Replying to or quoting a repost replies to or quotes
+// the original post, as Repost does. A reply belongs to the conversation of its
+// parent, or starts one under it when the parent is not a reply itself. The
+// hashtags and resolved mentions among entities are indexed along with the
+// post, in the same statement.
+func (r *repository) CreatePost(ctx context.Context, authorID int, text string, parentID, quoteOfID *int, entities []model.Entity) (*model.Post, error) {
+	rows, err := r.db.Query(ctx, `with target as (
+			select coalesce((select repost_of_id from posts where id = $3::int), $3::int) as parent_id,
+				coalesce((select repost_of_id from posts where id = $4::int), $4::int) as quote_of_id
+		), post as (
+			insert into posts (author_id, text, parent_id, root_id, kind, quote_of_id, entities)
+			select $1, $2, target.parent_id, (select coalesce(root_id, id) from posts where id = target.parent_id), case when $4::int is null then 'post' else 'quote' end, target.quote_of_id, $5
+			from target
+			returning id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities
+		), tags as (
+			insert into hashtags (name) select unnest($6::text[])
+			on conflict (name) do update set name = excluded.name
+			returning id
+		), post_tags as (
+			insert into post_hashtags (post_id, hashtag_id, created_at)
+			select post.id, tags.id, post.created_at from post, tags
+		), mentions as (
+			insert into post_mentions (post_id, user_id)
+			select post.id, unnest($7::int[]) from post
+		)
+		select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities from post`, authorID, text, parentID, quoteOfID, entities, model.Hashtags(entities), model.MentionedUserIDs(entities))
+	if err != nil {
+		log.Printf("error inserting post: %+v", err)
+		return nil, translateError(err)
+	}
+
+	post, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, translateError(err)
+	}
+
+	return &post, nil
+}
+
+func (r *repository) GetPost(ctx context.Context, id int) (*model.Post, error) {
+	rows, err := r.db.Query(ctx, "select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities from posts where id = $1", id)
+	if err != nil {
+		log.Printf("error querying post: %+v", err)
+		return nil, err
+	}
+
+	post, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		err = translateError(err)
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("error collecting rows: %+v", err)
+		}
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// GetUserIDsByHandles returns the ids of the users holding handles, keyed by
+// lower case handle. Handles nobody holds are left out.
+func (r *repository) GetUserIDsByHandles(ctx context.Context, handles []string) (map[string]int, error) {
+	rows, err := r.db.Query(ctx, "select lower(handle), id from users where lower(handle) = any($1) and deleted_at is null", handles)
+	if err != nil {
+		log.Printf("error querying handles: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ids := map[string]int{}
+	var handle string
+	var id int
+	_, err = pgx.ForEachRow(rows, []any{&handle, &id}, func() error {
+		ids[handle] = id
+		return nil
+	})
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetPosts returns the posts among ids that exist, in no particular order.
+func (r *repository) GetPosts(ctx context.Context, ids []int) ([]model.Post, error) {
+	rows, err := r.db.Query(ctx, "select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities from posts where id = any($1)", ids)
+	if err != nil {
+		log.Printf("error querying posts: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// Repost saves a repost of postID by userID. Reposting a repost shares the post
+// it reposts. Reposting the same post twice violates posts_repost_key.
+func (r *repository) Repost(ctx context.Context, userID, postID int) (*model.Post, error) {
+	rows, err := r.db.Query(ctx, `insert into posts (author_id, text, kind, repost_of_id)
+		select $1, '', 'repost', coalesce(repost_of_id, id) from posts where id = $2
+		returning id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities`, userID, postID)
+	if err != nil {
+		log.Printf("error inserting repost: %+v", err)
+		return nil, translateError(err)
+	}
+
+	post, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		err = translateError(err)
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("error collecting rows: %+v", err)
+		}
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// DeleteRepost removes userID's repost of postID and returns its id.
+func (r *repository) DeleteRepost(ctx context.Context, userID, postID int) (int, error) {
+	rows, err := r.db.Query(ctx, `delete from posts
+		where author_id = $1 and repost_of_id = (select coalesce(repost_of_id, id) from posts where id = $2)
+		returning id`, userID, postID)
+	if err != nil {
+		log.Printf("error deleting repost: %+v", err)
+		return 0, err
+	}
+
+	id, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[int])
+	if err != nil {
+		err = translateError(err)
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("error collecting rows: %+v", err)
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *repository) DeletePost(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, "delete from posts where id = $1", id)
+	if err != nil {
+		log.Printf("error deleting post: %+v", err)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetTimeline returns up to limit posts written by userID or by the accounts
+// userID follows, newest first, starting strictly after the given cursor.
+func (r *repository) GetTimeline(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error) {
+	var createdAt *time.Time
+	var id *int
+	if after != nil {
+		createdAt = &after.CreatedAt
+		id = &after.ID
+	}
+
+	rows, err := r.db.Query(ctx, `select p.id, p.author_id, p.text, p.created_at, p.like_count, p.parent_id, p.root_id, p.kind, p.repost_of_id, p.quote_of_id, p.repost_count, p.entities from posts p
+		where (p.author_id = $1 or p.author_id in (select followee_id from follows where follower_id = $1))
+		and ($2::timestamptz is null or (p.created_at, p.id) < ($2::timestamptz, $3::int))
+		order by p.created_at desc, p.id desc
+		limit $4`, userID, createdAt, id, limit)
+	if err != nil {
+		log.Printf("error querying timeline: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetPopularTimeline is GetTimeline restricted to followed accounts that have
+// more than minFollowers followers, whose posts are not fanned out on write.
+func (r *repository) GetPopularTimeline(ctx context.Context, userID, minFollowers int, after *model.Cursor, limit int) ([]model.Post, error) {
+	var createdAt *time.Time
+	var id *int
+	if after != nil {
+		createdAt = &after.CreatedAt
+		id = &after.ID
+	}
+
+	rows, err := r.db.Query(ctx, `select p.id, p.author_id, p.text, p.created_at, p.like_count, p.parent_id, p.root_id, p.kind, p.repost_of_id, p.quote_of_id, p.repost_count, p.entities from posts p
+		where p.author_id in (
+			select f.followee_id from follows f
+			join users u on u.id = f.followee_id
+			where f.follower_id = $1 and u.follower_count > $2
+		)
+		and ($3::timestamptz is null or (p.created_at, p.id) < ($3::timestamptz, $4::int))
+		order by p.created_at desc, p.id desc
+		limit $5`, userID, minFollowers, createdAt, id, limit)
+	if err != nil {
+		log.Printf("error querying popular timeline: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetAuthorPosts returns the latest limit posts written by authorID, newest
+// first.
+func (r *repository) GetAuthorPosts(ctx context.Context, authorID, limit int) ([]model.Post, error) {
+	rows, err := r.db.Query(ctx, `select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities from posts
+		where author_id = $1
+		order by created_at desc, id desc
+		limit $2`, authorID, limit)
+	if err != nil {
+		log.Printf("error querying author posts: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetPostLikes returns the like and repost counts of each of postIDs that
+// exists and whether userID likes it, in a single query.
+func (r *repository) GetPostLikes(ctx context.Context, userID int, postIDs []int) ([]model.PostLikes, error) {
+	rows, err := r.db.Query(ctx, `select p.id as post_id, p.like_count, p.repost_count,
+		exists (select 1 from likes l where l.post_id = p.id and l.user_id = $1) as liked
+		from posts p where p.id = any($2)`, userID, postIDs)
+	if err != nil {
+		log.Printf("error querying post likes: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	likes, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.PostLikes])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return likes, nil
+}
+
+// GetAncestors returns the chain of posts id replies to, starting with the
+// conversation's first post and ending with id's parent.
+func (r *repository) GetAncestors(ctx context.Context, id int) ([]model.Post, error) {
+	rows, err := r.db.Query(ctx, `with recursive ancestors as (
+			select p.*, 1 as depth from posts p where p.id = (select parent_id from posts where id = $1)
+			union all
+			select p.*, a.depth + 1 from posts p join ancestors a on p.id = a.parent_id
+		)
+		select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities from ancestors
+		order by depth desc`, id)
+	if err != nil {
+		log.Printf("error querying ancestors: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetDescendants returns up to limit replies to id and replies to those, depth
+// first, starting strictly after the reply at path after.
+func (r *repository) GetDescendants(ctx context.Context, id int, after []int, limit int) ([]model.ThreadPost, error) {
+	rows, err := r.db.Query(ctx, `with recursive descendants as (
+			select p.*, array[p.id] as path from posts p where p.parent_id = $1
+			union all
+			select p.*, d.path || p.id from posts p join descendants d on p.parent_id = d.id
+		)
+		select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities, path from descendants
+		where $2::int[] is null or path > $2::int[]
+		order by path
+		limit $3`, id, after, limit)
+	if err != nil {
+		log.Printf("error querying descendants: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.ThreadPost])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,702 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/util"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestCreatePost_ReturnsPost(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := model.Post{
+		ID: 1,
+		AuthorID: 2,
+		Text: "hello",
+		CreatedAt: dummyTime,
+		Kind: model.PostKindPost,
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).AddRow(1, 2, "hello", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil))
+
+	mockDb.ExpectQuery("insert into posts").WithArgs(2, "hello", (*int)(nil), (*int)(nil), []model.Entity(nil), []string{}, []int{}).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.CreatePost(context.Background(), 2, "hello", nil, nil, nil)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreatePost_ReturnsError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	expected := errors.New("test error")
+
+	mockDb.ExpectQuery("insert into posts").WithArgs(2, "hello", (*int)(nil), (*int)(nil), []model.Entity(nil), []string{}, []int{}).WillReturnError(expected)
+
+	// act
+	_, actual := repo.CreatePost(context.Background(), 2, "hello", nil, nil, nil)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreatePost_Reply_ReturnsReply(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	parentID, rootID := 3, 1
+	dummyTime := time.Now()
+	expected := model.Post{
+		ID: 4,
+		AuthorID: 2,
+		Text: "hello",
+		CreatedAt: dummyTime,
+		Kind: model.PostKindPost,
+		ParentID: &parentID,
+		RootID: &rootID,
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).AddRow(4, 2, "hello", dummyTime, 0, &parentID, &rootID, model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil))
+
+	mockDb.ExpectQuery(`insert into posts \(author_id, text, parent_id, root_id, kind, quote_of_id, entities\)\s+select \$1, \$2, target.parent_id, \(select coalesce\(root_id, id\) from posts where id = target.parent_id\)`).WithArgs(2, "hello", &parentID, (*int)(nil), []model.Entity(nil), []string{}, []int{}).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.CreatePost(context.Background(), 2, "hello", &parentID, nil, nil)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreatePost_NoParent_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	parentID := 3
+
+	mockDb.ExpectQuery("insert into posts").WithArgs(2, "hello", &parentID, (*int)(nil), []model.Entity(nil), []string{}, []int{}).WillReturnError(&pgconn.PgError{Code: foreignKeyViolation, ConstraintName: "posts_parent_id_fkey"})
+
+	// act
+	_, actual := repo.CreatePost(context.Background(), 2, "hello", &parentID, nil, nil)
+
+	// assert
+	if !errors.Is(actual, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, actual)
+	}
+
+	var reference *ReferenceError
+	if !errors.As(actual, &reference) || reference.Constraint != "posts_parent_id_fkey" {
+		t.Errorf("expected the parent foreign key to be reported, actual: %+v", actual)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreatePost_QuoteOfRepost_QuotesOriginal(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	repostID, originalID := 5, 1
+	dummyTime := time.Now()
+	expected := model.Post{
+		ID: 6,
+		AuthorID: 2,
+		Text: "hello",
+		CreatedAt: dummyTime,
+		Kind: model.PostKindQuote,
+		QuoteOfID: &originalID,
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).AddRow(6, 2, "hello", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindQuote, (*int)(nil), &originalID, 0, []model.Entity(nil))
+
+	mockDb.ExpectQuery(`coalesce\(\(select repost_of_id from posts where id = \$4::int\), \$4::int\) as quote_of_id`).WithArgs(2, "hello", (*int)(nil), &repostID, []model.Entity(nil), []string{}, []int{}).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.CreatePost(context.Background(), 2, "hello", nil, &repostID, nil)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetPost_ReturnsPost(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := model.Post{
+		ID: 1,
+		AuthorID: 2,
+		Text: "hello",
+		CreatedAt: dummyTime,
+		Kind: model.PostKindPost,
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).AddRow(1, 2, "hello", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil))
+
+	mockDb.ExpectQuery("select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities from posts").WithArgs(1).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetPost(context.Background(), 1)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetPostNoPost_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"})
+
+	mockDb.ExpectQuery("select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities from posts").WithArgs(1).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetPost(context.Background(), 1)
+
+	// assert
+	if actual != nil {
+		t.Errorf("expected nil post")
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeletePost_ReturnsNoError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("delete from posts").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	// act
+	actual := repo.DeletePost(context.Background(), 1)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeletePost_ReturnsError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	expected := errors.New("test error")
+
+	mockDb.ExpectExec("delete from posts").WithArgs(1).WillReturnError(expected)
+
+	// act
+	actual := repo.DeletePost(context.Background(), 1)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetTimeline_ReturnsPosts(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := []model.Post{
+		{
+			ID: 2,
+			AuthorID: 3,
+			Text: "hello",
+			CreatedAt: dummyTime,
+			Kind: model.PostKindPost,
+		},
+	}
+
+	after := model.Cursor{CreatedAt: dummyTime, ID: 5}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).AddRow(2, 3, "hello", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil))
+
+	mockDb.ExpectQuery("select p.id, p.author_id, p.text, p.created_at, p.like_count, p.parent_id, p.root_id, p.kind, p.repost_of_id, p.quote_of_id, p.repost_count, p.entities from posts p").WithArgs(1, &after.CreatedAt, &after.ID, 11).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetTimeline(context.Background(), 1, &after, 11)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetTimelineFails_ReturnsError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery("select p.id, p.author_id, p.text, p.created_at, p.like_count, p.parent_id, p.root_id, p.kind, p.repost_of_id, p.quote_of_id, p.repost_count, p.entities from posts p").WithArgs(1, (*time.Time)(nil), (*int)(nil), 11).WillReturnError(errors.New("test error"))
+
+	// act
+	_, err = repo.GetTimeline(context.Background(), 1, nil, 11)
+
+	// assert
+	if err == nil || err.Error() != "test error" {
+		t.Errorf("expected: %+v, actual: %+v", "test error", err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetPostLikes_ReturnsLikes(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	expected := []model.PostLikes{
+		{PostID: 1, LikeCount: 3, RepostCount: 2, Liked: true},
+		{PostID: 2, LikeCount: 0, RepostCount: 0, Liked: false},
+	}
+
+	mockRows := mockDb.NewRows([]string{"post_id", "like_count", "repost_count", "liked"}).AddRow(1, 3, 2, true).AddRow(2, 0, 0, false)
+
+	mockDb.ExpectQuery("select p.id as post_id, p.like_count, p.repost_count").WithArgs(5, []int{1, 2}).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetPostLikes(context.Background(), 5, []int{1, 2})
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetAncestors_ReturnsOldestFirst(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	rootID := 1
+	dummyTime := time.Now()
+	expected := []model.Post{
+		{ID: 1, AuthorID: 2, Text: "root", CreatedAt: dummyTime, Kind: model.PostKindPost},
+		{ID: 2, AuthorID: 3, Text: "reply", CreatedAt: dummyTime, Kind: model.PostKindPost, ParentID: &rootID, RootID: &rootID},
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).
+		AddRow(1, 2, "root", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil)).
+		AddRow(2, 3, "reply", dummyTime, 0, &rootID, &rootID, model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil))
+
+	mockDb.ExpectQuery(`with recursive ancestors as (.+) order by depth desc`).WithArgs(3).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetAncestors(context.Background(), 3)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetDescendants_ReturnsPaths(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	rootID, parentID := 1, 4
+	dummyTime := time.Now()
+	expected := []model.ThreadPost{
+		{Post: model.Post{ID: 5, AuthorID: 2, Text: "reply", CreatedAt: dummyTime, Kind: model.PostKindPost, ParentID: &parentID, RootID: &rootID}, Path: []int{4, 5}},
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities", "path"}).
+		AddRow(5, 2, "reply", dummyTime, 0, &parentID, &rootID, model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil), []int{4, 5})
+
+	mockDb.ExpectQuery(`with recursive descendants as (.+) where \$2::int\[\] is null or path > \$2::int\[\]\s+order by path\s+limit \$3`).WithArgs(1, []int{2}, 10).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetDescendants(context.Background(), 1, []int{2}, 10)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if actual[0].Path[1] != 5 {
+		t.Errorf("expected path %v, actual: %v", expected[0].Path, actual[0].Path)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRepost_ReturnsRepost(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	originalID := 1
+	dummyTime := time.Now()
+	expected := model.Post{ID: 5, AuthorID: 3, CreatedAt: dummyTime, Kind: model.PostKindRepost, RepostOfID: &originalID}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).
+		AddRow(5, 3, "", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindRepost, &originalID, (*int)(nil), 0, []model.Entity(nil))
+
+	mockDb.ExpectQuery(`insert into posts \(author_id, text, kind, repost_of_id\)\s+select \$1, '', 'repost', coalesce\(repost_of_id, id\) from posts where id = \$2`).WithArgs(3, 1).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.Repost(context.Background(), 3, 1)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRepostDuplicate_ReturnsConflict(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery("insert into posts").WithArgs(3, 1).WillReturnError(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "posts_repost_key"})
+
+	// act
+	_, err = repo.Repost(context.Background(), 3, 1)
+
+	// assert
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Constraint != "posts_repost_key" {
+		t.Errorf("expected a conflict on posts_repost_key, actual: %+v", err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRepostNoPost_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"})
+
+	mockDb.ExpectQuery("insert into posts").WithArgs(3, 1).WillReturnRows(mockRows)
+
+	// act
+	_, err = repo.Repost(context.Background(), 3, 1)
+
+	// assert
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteRepost_ReturnsRepostID(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockRows := mockDb.NewRows([]string{"id"}).AddRow(5)
+
+	mockDb.ExpectQuery(`delete from posts\s+where author_id = \$1 and repost_of_id = \(select coalesce\(repost_of_id, id\) from posts where id = \$2\)`).WithArgs(3, 1).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.DeleteRepost(context.Background(), 3, 1)
+
+	// assert
+	if err != nil || actual != 5 {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", 5, actual, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteRepostNoRepost_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery("delete from posts").WithArgs(3, 1).WillReturnRows(mockDb.NewRows([]string{"id"}))
+
+	// act
+	_, err = repo.DeleteRepost(context.Background(), 3, 1)
+
+	// assert
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreatePost_WithEntities_IndexesThem(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	userID := 3
+	entities := []model.Entity{
+		{Type: model.EntityHashtag, Start: 0, End: 3, Text: "go"},
+		{Type: model.EntityMention, Start: 4, End: 10, Text: "alice", UserID: &userID},
+		{Type: model.EntityHashtag, Start: 11, End: 14, Text: "go"},
+	}
+
+	dummyTime := time.Now()
+	expected := model.Post{
+		ID: 1,
+		AuthorID: 2,
+		Text: "#go @alice #Go",
+		CreatedAt: dummyTime,
+		Kind: model.PostKindPost,
+		Entities: entities,
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).AddRow(1, 2, "#go @alice #Go", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindPost, (*int)(nil), (*int)(nil), 0, entities)
+
+	mockDb.ExpectQuery(`insert into hashtags \(name\) select unnest\(\$6::text\[\]\)(.|\s)+insert into post_hashtags(.|\s)+insert into post_mentions \(post_id, user_id\)\s+select post.id, unnest\(\$7::int\[\]\)`).WithArgs(2, "#go @alice #Go", (*int)(nil), (*int)(nil), entities, []string{"go"}, []int{3}).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.CreatePost(context.Background(), 2, "#go @alice #Go", nil, nil, entities)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetUserIDsByHandles_ReturnsIDs(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	expected := map[string]int{"alice": 3}
+
+	mockRows := mockDb.NewRows([]string{"lower", "id"}).AddRow("alice", 3)
+
+	mockDb.ExpectQuery(`select lower\(handle\), id from users where lower\(handle\) = any\(\$1\) and deleted_at is null`).WithArgs([]string{"alice", "nobody"}).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetUserIDsByHandles(context.Background(), []string{"alice", "nobody"})
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
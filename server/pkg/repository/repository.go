@@ -2,27 +2,102 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"log"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
-type dbConn interface {
+// maxTxAttempts bounds how often WithTx runs a transaction that keeps failing
+// to serialize.
+const maxTxAttempts = 3
+
+type querier interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
 }
 
+type dbConn interface {
+	querier
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
 type Repository interface {
 	UserRepository
+	PostRepository
+	FollowRepository
+	TimelineRepository
+	LikeRepository
+	BookmarkRepository
+	SearchRepository
+	TrendRepository
+
+	// WithTx runs fn in a serializable transaction, passing it a Repository
+	// bound to that transaction. The transaction commits if fn returns nil and
+	// rolls back otherwise. fn is run again when the transaction fails to
+	// serialize, so it must not have side effects outside the database. Calling
+	// WithTx on a Repository already bound to a transaction runs fn in it.
+	WithTx(ctx context.Context, fn func(Repository) error) error
 }
 
 type repository struct {
-	db dbConn
+	db querier
+	// conn starts transactions. It is nil for a repository bound to one.
+	conn dbConn
 }
 
 func New(db dbConn) Repository {
 	return &repository{
 		db: db,
+		conn: db,
+	}
+}
+
+func (r *repository) WithTx(ctx context.Context, fn func(Repository) error) error {
+	if r.conn == nil {
+		return fn(r)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := r.runTx(ctx, fn)
+		if attempt < maxTxAttempts && isSerializationFailure(err) {
+			log.Printf("retrying transaction after attempt %d: %+v", attempt, err)
+			continue
+		}
+
+		return err
 	}
 }
 
+func (r *repository) runTx(ctx context.Context, fn func(Repository) error) error {
+	tx, err := r.conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		log.Printf("error starting transaction: %+v", err)
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := fn(&repository{db: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("error committing transaction: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+// isSerializationFailure reports whether err means the transaction lost a race
+// with a concurrent one and can be retried as it is.
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
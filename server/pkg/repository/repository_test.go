@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+var serializable = pgx.TxOptions{IsoLevel: pgx.Serializable}
+
+func TestWithTx_Commits(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectBeginTx(serializable)
+	mockDb.ExpectExec("delete from posts").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mockDb.ExpectExec("delete from timeline_entries").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	mockDb.ExpectCommit()
+
+	// act
+	err = repo.WithTx(context.Background(), func(tx Repository) error {
+		if err := tx.DeletePost(context.Background(), 1); err != nil {
+			return err
+		}
+		return tx.DeleteTimelineEntries(context.Background(), 1)
+	})
+
+	// assert
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTx_RollsBackOnError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	expected := errors.New("test error")
+
+	mockDb.ExpectBeginTx(serializable)
+	mockDb.ExpectExec("delete from posts").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mockDb.ExpectRollback()
+
+	// act
+	actual := repo.WithTx(context.Background(), func(tx Repository) error {
+		if err := tx.DeletePost(context.Background(), 1); err != nil {
+			return err
+		}
+		return expected
+	})
+
+	// assert
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTx_RetriesSerializationFailures(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectBeginTx(serializable)
+	mockDb.ExpectExec("delete from posts").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mockDb.ExpectCommit().WillReturnError(&pgconn.PgError{Code: serializationFailure})
+	mockDb.ExpectRollback()
+	mockDb.ExpectBeginTx(serializable)
+	mockDb.ExpectExec("delete from posts").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mockDb.ExpectCommit()
+
+	attempts := 0
+
+	// act
+	err = repo.WithTx(context.Background(), func(tx Repository) error {
+		attempts++
+		return tx.DeletePost(context.Background(), 1)
+	})
+
+	// assert
+	if err != nil || attempts != 2 {
+		t.Errorf("expected: %+v after %d attempts, actual: %+v after %d attempts", nil, 2, err, attempts)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTx_GivesUpAfterMaxAttempts(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	for i := 0; i < maxTxAttempts; i++ {
+		mockDb.ExpectBeginTx(serializable)
+		mockDb.ExpectExec("delete from posts").WithArgs(1).WillReturnError(&pgconn.PgError{Code: serializationFailure})
+		mockDb.ExpectRollback()
+	}
+
+	// act
+	err = repo.WithTx(context.Background(), func(tx Repository) error {
+		return tx.DeletePost(context.Background(), 1)
+	})
+
+	// assert
+	if !isSerializationFailure(err) {
+		t.Errorf("expected a serialization failure, actual: %+v", err)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTx_Nested_JoinsOuterTransaction(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectBeginTx(serializable)
+	mockDb.ExpectExec("delete from posts").WithArgs(1).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mockDb.ExpectCommit()
+
+	// act
+	err = repo.WithTx(context.Background(), func(tx Repository) error {
+		return tx.WithTx(context.Background(), func(inner Repository) error {
+			return inner.DeletePost(context.Background(), 1)
+		})
+	})
+
+	// assert
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
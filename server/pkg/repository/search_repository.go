@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"x/pkg/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SearchRepository finds users and posts through the full-text search columns
+// kept up to date by Postgres.
+type SearchRepository interface {
+	SearchUsers(ctx context.Context, query string, after *model.SearchCursor, limit int) ([]model.SearchedUser, error)
+	SearchPosts(ctx context.Context, query string, after *model.SearchCursor, limit int) ([]model.SearchedPost, error)
+}
+
+// SearchUsers returns up to limit users whose handle or name matches query,
+// best match first, starting strictly after the given cursor. query is in the
+// to_tsquery syntax, so prefixes can be matched with :*. Handles weigh more
+// than names.
+func (r *repository) SearchUsers(ctx context.Context, query string, after *model.SearchCursor, limit int) ([]model.SearchedUser, error) {
+	rank, id := searchPosition(after)
+
+	rows, err := r.db.Query(ctx, `select id, name, handle, email, upserted_at, bio, dob, rank
+		from (
+			select u.id, u.name, u.handle, u.email, u.upserted_at, u.bio, u.dob, ts_rank(u.search, q) as rank
+			from users u, to_tsquery('simple', $1) q
+			where u.search @@ q and u.deleted_at is null
+		) matches
+		where $2::real is null or (rank, id) < ($2::real, $3::int)
+		order by rank desc, id desc
+		limit $4`, query, rank, id, limit)
+	if err != nil {
+		log.Printf("error searching users: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.SearchedUser])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// SearchPosts returns up to limit posts whose text matches query, best match
+// first, starting strictly after the given cursor. query is in the syntax of
+// web search engines understood by websearch_to_tsquery.
+func (r *repository) SearchPosts(ctx context.Context, query string, after *model.SearchCursor, limit int) ([]model.SearchedPost, error) {
+	rank, id := searchPosition(after)
+
+	rows, err := r.db.Query(ctx, `select id, author_id, text, created_at, like_count, parent_id, root_id, kind, repost_of_id, quote_of_id, repost_count, entities, rank
+		from (
+			select p.id, p.author_id, p.text, p.created_at, p.like_count, p.parent_id, p.root_id, p.kind, p.repost_of_id, p.quote_of_id, p.repost_count, p.entities, ts_rank(p.search, q) as rank
+			from posts p, websearch_to_tsquery('simple', $1) q
+			where p.search @@ q
+		) matches
+		where $2::real is null or (rank, id) < ($2::real, $3::int)
+		order by rank desc, id desc
+		limit $4`, query, rank, id, limit)
+	if err != nil {
+		log.Printf("error searching posts: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.SearchedPost])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func searchPosition(after *model.SearchCursor) (*float32, *int) {
+	if after == nil {
+		return nil, nil
+	}
+
+	return &after.Rank, &after.ID
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/util"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestSearchUsers_ReturnsUsers(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := []model.SearchedUser{
+		{
+			User: model.User{
+				ID: 2,
+				Name: "Michael Scott",
+				Handle: "michael_scott",
+				Email: "michael@example.com",
+				UpsertedAt: dummyTime,
+				Bio: "bio",
+			},
+			Rank: 0.6,
+		},
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob", "rank"}).AddRow(2, "Michael Scott", "michael_scott", "michael@example.com", dummyTime, "bio", (*model.Date)(nil), float32(0.6))
+
+	mockDb.ExpectQuery(`from users u, to_tsquery\('simple', \$1\) q\s+where u.search @@ q and u.deleted_at is null`).WithArgs("mich:*", (*float32)(nil), (*int)(nil), 11).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.SearchUsers(context.Background(), "mich:*", nil, 11)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSearchPosts_AfterCursor_ReturnsPosts(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := []model.SearchedPost{
+		{
+			Post: model.Post{
+				ID: 3,
+				AuthorID: 2,
+				Text: "hello world",
+				CreatedAt: dummyTime,
+				Kind: model.PostKindPost,
+			},
+			Rank: 0.1,
+		},
+	}
+
+	after := model.SearchCursor{Type: model.SearchPosts, Query: "hello", Rank: 0.2, ID: 7}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities", "rank"}).AddRow(3, 2, "hello world", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil), float32(0.1))
+
+	mockDb.ExpectQuery(`from posts p, websearch_to_tsquery\('simple', \$1\) q(.|\s)+where \$2::real is null or \(rank, id\) < \(\$2::real, \$3::int\)\s+order by rank desc, id desc`).WithArgs("hello", &after.Rank, &after.ID, 11).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.SearchPosts(context.Background(), "hello", &after, 11)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSearchPostsFails_ReturnsError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectQuery("websearch_to_tsquery").WithArgs("hello", (*float32)(nil), (*int)(nil), 11).WillReturnError(errors.New("test error"))
+
+	// act
+	_, err = repo.SearchPosts(context.Background(), "hello", nil, 11)
+
+	// assert
+	if err == nil || err.Error() != "test error" {
+		t.Errorf("expected: %+v, actual: %+v", "test error", err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
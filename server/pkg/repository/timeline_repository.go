@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+	"x/pkg/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TimelineRepository stores precomputed home timelines, one row per post per
+// reader, for posts that were fanned out on write.
+type TimelineRepository interface {
+	PushTimelineEntries(ctx context.Context, userIDs []int, post model.Post) error
+	GetTimelineEntries(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error)
+	DeleteTimelineEntries(ctx context.Context, postID int) error
+	AddTimelineEntries(ctx context.Context, userID int, posts []model.Post) error
+	DeleteAuthorTimelineEntries(ctx context.Context, userID, authorID int) error
+}
+
+func (r *repository) PushTimelineEntries(ctx context.Context, userIDs []int, post model.Post) error {
+	_, err := r.db.Exec(ctx, "insert into timeline_entries (user_id, post_id, created_at) select unnest($1::int[]), $2, $3 on conflict do nothing", userIDs, post.ID, post.CreatedAt)
+	if err != nil {
+		log.Printf("error inserting timeline entries: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *repository) GetTimelineEntries(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error) {
+	var createdAt *time.Time
+	var id *int
+	if after != nil {
+		createdAt = &after.CreatedAt
+		id = &after.ID
+	}
+
+	rows, err := r.db.Query(ctx, `select p.id, p.author_id, p.text, p.created_at, p.like_count, p.parent_id, p.root_id, p.kind, p.repost_of_id, p.quote_of_id, p.repost_count, p.entities from timeline_entries t
+		join posts p on p.id = t.post_id
+		where t.user_id = $1
+		and ($2::timestamptz is null or (t.created_at, t.post_id) < ($2::timestamptz, $3::int))
+		order by t.created_at desc, t.post_id desc
+		limit $4`, userID, createdAt, id, limit)
+	if err != nil {
+		log.Printf("error querying timeline entries: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	posts, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Post])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (r *repository) DeleteTimelineEntries(ctx context.Context, postID int) error {
+	_, err := r.db.Exec(ctx, "delete from timeline_entries where post_id = $1", postID)
+	if err != nil {
+		log.Printf("error deleting timeline entries: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+// AddTimelineEntries adds posts to the timeline of userID, skipping those
+// already in it.
+func (r *repository) AddTimelineEntries(ctx context.Context, userID int, posts []model.Post) error {
+	ids := make([]int, len(posts))
+	createdAts := make([]time.Time, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+		createdAts[i] = post.CreatedAt
+	}
+
+	_, err := r.db.Exec(ctx, "insert into timeline_entries (user_id, post_id, created_at) select $1, unnest($2::int[]), unnest($3::timestamptz[]) on conflict do nothing", userID, ids, createdAts)
+	if err != nil {
+		log.Printf("error inserting timeline entries: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAuthorTimelineEntries removes the posts of authorID from the timeline
+// of userID.
+func (r *repository) DeleteAuthorTimelineEntries(ctx context.Context, userID, authorID int) error {
+	_, err := r.db.Exec(ctx, "delete from timeline_entries t using posts p where p.id = t.post_id and t.user_id = $1 and p.author_id = $2", userID, authorID)
+	if err != nil {
+		log.Printf("error deleting timeline entries: %+v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/util"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestPushTimelineEntries_ReturnsNoError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	post := model.Post{ID: 1, AuthorID: 2, Text: "hello", CreatedAt: time.Now()}
+
+	mockDb.ExpectExec("insert into timeline_entries").WithArgs([]int{2, 3}, 1, post.CreatedAt).WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+	// act
+	actual := repo.PushTimelineEntries(context.Background(), []int{2, 3}, post)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetTimelineEntries_ReturnsPosts(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := []model.Post{{ID: 1, AuthorID: 2, Text: "hello", CreatedAt: dummyTime, Kind: model.PostKindPost}}
+
+	mockRows := mockDb.NewRows([]string{"id", "author_id", "text", "created_at", "like_count", "parent_id", "root_id", "kind", "repost_of_id", "quote_of_id", "repost_count", "entities"}).AddRow(1, 2, "hello", dummyTime, 0, (*int)(nil), (*int)(nil), model.PostKindPost, (*int)(nil), (*int)(nil), 0, []model.Entity(nil))
+
+	mockDb.ExpectQuery("select (.+) from timeline_entries t").WithArgs(3, (*time.Time)(nil), (*int)(nil), 10).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetTimelineEntries(context.Background(), 3, nil, 10)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteTimelineEntries_ReturnsError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	expected := errors.New("test error")
+
+	mockDb.ExpectExec("delete from timeline_entries").WithArgs(1).WillReturnError(expected)
+
+	// act
+	actual := repo.DeleteTimelineEntries(context.Background(), 1)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAddTimelineEntries_ReturnsNoError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	posts := []model.Post{{ID: 2, CreatedAt: dummyTime}, {ID: 1, CreatedAt: dummyTime.Add(-time.Hour)}}
+
+	mockDb.ExpectExec("insert into timeline_entries").WithArgs(3, []int{2, 1}, []time.Time{posts[0].CreatedAt, posts[1].CreatedAt}).WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+	// act
+	actual := repo.AddTimelineEntries(context.Background(), 3, posts)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteAuthorTimelineEntries_ReturnsNoError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec(`delete from timeline_entries t using posts p where p.id = t.post_id and t.user_id = \$1 and p.author_id = \$2`).WithArgs(3, 2).WillReturnResult(pgxmock.NewResult("DELETE", 4))
+
+	// act
+	actual := repo.DeleteAuthorTimelineEntries(context.Background(), 3, 2)
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	// assert
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+	"x/pkg/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TrendRepository reads how much hashtags have been used lately.
+type TrendRepository interface {
+	GetTrends(ctx context.Context, now time.Time, window, halfLife time.Duration, limit int) ([]model.Trend, error)
+}
+
+// GetTrends returns up to limit hashtags used within window before now, highest
+// score first. Every post using a hashtag adds to its score a weight halving
+// every halfLife, from 1 for a post made at now.
+func (r *repository) GetTrends(ctx context.Context, now time.Time, window, halfLife time.Duration, limit int) ([]model.Trend, error) {
+	rows, err := r.db.Query(ctx, `select h.name, count(*) as post_count, sum(power(0.5, extract(epoch from $1::timestamptz - ph.created_at) / $3::float8)) as score
+		from post_hashtags ph
+		join hashtags h on h.id = ph.hashtag_id
+		where ph.created_at > $2::timestamptz and ph.created_at <= $1::timestamptz
+		group by h.name
+		order by score desc, h.name
+		limit $4`, now, now.Add(-window), halfLife.Seconds(), limit)
+	if err != nil {
+		log.Printf("error querying trends: %+v", err)
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	trends, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Trend])
+	if err != nil {
+		log.Printf("error collecting rows: %+v", err)
+		return nil, err
+	}
+
+	return trends, nil
+}
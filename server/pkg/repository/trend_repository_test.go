@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/util"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestGetTrends_ReturnsTrends(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	now := time.Now()
+	expected := []model.Trend{
+		{Hashtag: "golang", PostCount: 3, Score: 2.5},
+		{Hashtag: "café", PostCount: 1, Score: 0.5},
+	}
+
+	mockRows := mockDb.NewRows([]string{"name", "post_count", "score"}).AddRow("golang", 3, 2.5).AddRow("café", 1, 0.5)
+
+	mockDb.ExpectQuery(`from post_hashtags ph\s+join hashtags h on h.id = ph.hashtag_id\s+where ph.created_at > \$2::timestamptz and ph.created_at <= \$1::timestamptz`).WithArgs(now, now.Add(-time.Hour), float64(900), 10).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetTrends(context.Background(), now, time.Hour, 15*time.Minute, 10)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetTrendsFails_ReturnsError(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	now := time.Now()
+
+	mockDb.ExpectQuery("from post_hashtags").WithArgs(now, now.Add(-time.Hour), float64(900), 10).WillReturnError(errors.New("test error"))
+
+	// act
+	_, err = repo.GetTrends(context.Background(), now, time.Hour, 15*time.Minute, 10)
+
+	// assert
+	if err == nil || err.Error() != "test error" {
+		t.Errorf("expected: %+v, actual: %+v", "test error", err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
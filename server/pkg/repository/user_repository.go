@@ -2,22 +2,63 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 	"x/pkg/model"
 
 	"github.com/jackc/pgx/v5"
 )
 
 type UserRepository interface {
-	GetAllUsers() ([]model.User, error)
-	CreateUser(name, email, bio string, dob interface{}) error
-	UpdateUser(id int, name, email, bio string, dob interface{}) error
-	GetUser(id int) (*model.User, error)
-	GetUserByEmail(email string) (*model.User, error)
+	GetAllUsers(ctx context.Context, query model.UserQuery) ([]model.User, error)
+	CreateUser(ctx context.Context, name, handle, email, bio string, dob *model.Date, passwordHash string) error
+	UpdateUser(ctx context.Context, id int, name, handle, email, bio string, dob *model.Date, version time.Time) (*model.User, error)
+	GetUser(ctx context.Context, id int) (*model.User, error)
+	GetUserByHandle(ctx context.Context, handle string) (*model.User, error)
+	HandleExists(ctx context.Context, handle string) (bool, error)
+	GetCredentials(ctx context.Context, email string) (*model.Credentials, error)
+	DeleteUser(ctx context.Context, id int) error
+	ReactivateUser(ctx context.Context, id int) error
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
-func (r *repository) GetAllUsers() ([]model.User, error) {
-	rows, err := r.db.Query(context.Background(), "select id, name, email, upserted_at, bio, dob from users")
+// likeEscaper escapes the wildcards of like patterns, so that a value is matched
+// literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// GetAllUsers returns up to query.Limit users whose name starts with
+// query.NamePrefix, ignoring case, ordered by the query's sort and then id,
+// starting strictly after query.After. The prefix is matched with like rather
+// than starts_with so that it can use the index on lower(name).
+func (r *repository) GetAllUsers(ctx context.Context, query model.UserQuery) ([]model.User, error) {
+	column, direction, comparison := "name", "asc", ">"
+	if query.SortBy == model.SortByUpsertedAt {
+		column = "upserted_at"
+	}
+	if query.Descending {
+		direction, comparison = "desc", "<"
+	}
+
+	sql := "select id, name, handle, email, upserted_at, bio, dob from users where deleted_at is null and lower(name) like lower($1) || '%'"
+	args := []any{likeEscaper.Replace(query.NamePrefix)}
+
+	if query.After != nil {
+		var key any = query.After.Name
+		if query.SortBy == model.SortByUpsertedAt {
+			key = query.After.UpsertedAt
+		}
+
+		sql += fmt.Sprintf(" and (%s, id) %s ($2, $3)", column, comparison)
+		args = append(args, key, query.After.ID)
+	}
+
+	sql += fmt.Sprintf(" order by %s %s, id %s limit $%d", column, direction, direction, len(args)+1)
+	args = append(args, query.Limit)
+
+	rows, err := r.db.Query(ctx, sql, args...)
 	if err != nil {
 		log.Printf("error querying users: %+v", err)
 		return nil, err
@@ -34,34 +75,38 @@ func (r *repository) GetAllUsers() ([]model.User, error) {
 	return users, nil
 }
 
-func (r *repository) CreateUser(name, email, bio string, dob interface{}) error {
-	_, err := r.db.Exec(context.Background(), "insert into users (name, email, bio, dob) values ($1, $2, $3, $4)", name, email, bio, dob)
+func (r *repository) CreateUser(ctx context.Context, name, handle, email, bio string, dob *model.Date, passwordHash string) error {
+	_, err := r.db.Exec(ctx, "insert into users (name, handle, email, bio, dob, password_hash) values ($1, $2, $3, $4, $5, $6)", name, handle, email, bio, dob, passwordHash)
 	if err != nil {
 		log.Printf("error inserting user: %+v", err)
-		return err
+		return translateError(err)
 	}
 
 	return nil
 }
 
-func (r *repository) GetUser(id int) (*model.User, error) {
-	rows, err := r.db.Query(context.Background(), "select id, name, email, upserted_at, bio, dob from users where id = $1", id)
+func (r *repository) GetUser(ctx context.Context, id int) (*model.User, error) {
+	rows, err := r.db.Query(ctx, "select id, name, handle, email, upserted_at, bio, dob from users where id = $1 and deleted_at is null", id)
 	if err != nil {
 		log.Printf("error querying user: %+v", err)
 		return nil, err
 	}
-	
+
 	user, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.User])
 	if err != nil {
-		log.Printf("error collecting rows: %+v", err)
+		err = translateError(err)
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("error collecting rows: %+v", err)
+		}
 		return nil, err
 	}
 
 	return &user, nil
 }
 
-func (r *repository) GetUserByEmail(email string) (*model.User, error) {
-	rows, err := r.db.Query(context.Background(), "select id, name, email, upserted_at, bio, dob from users where email = $1", email)
+// GetUserByHandle finds a user by handle, ignoring case.
+func (r *repository) GetUserByHandle(ctx context.Context, handle string) (*model.User, error) {
+	rows, err := r.db.Query(ctx, "select id, name, handle, email, upserted_at, bio, dob from users where lower(handle) = lower($1) and deleted_at is null", handle)
 	if err != nil {
 		log.Printf("error querying user: %+v", err)
 		return nil, err
@@ -69,22 +114,114 @@ func (r *repository) GetUserByEmail(email string) (*model.User, error) {
 
 	user, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.User])
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, nil
+		err = translateError(err)
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("error collecting rows: %+v", err)
 		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// HandleExists reports whether anyone holds handle, ignoring case. Deleted
+// users keep their handle until they are purged.
+func (r *repository) HandleExists(ctx context.Context, handle string) (bool, error) {
+	rows, err := r.db.Query(ctx, "select exists (select 1 from users where lower(handle) = lower($1))", handle)
+	if err != nil {
+		log.Printf("error querying handle: %+v", err)
+		return false, err
+	}
+
+	exists, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[bool])
+	if err != nil {
 		log.Printf("error collecting rows: %+v", err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// GetCredentials also finds deactivated users, so that they can log in again
+// to reactivate their account.
+func (r *repository) GetCredentials(ctx context.Context, email string) (*model.Credentials, error) {
+	rows, err := r.db.Query(ctx, "select id, coalesce(password_hash, '') as password_hash, deleted_at from users where email = $1", email)
+	if err != nil {
+		log.Printf("error querying credentials: %+v", err)
+		return nil, err
+	}
+
+	credentials, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.Credentials])
+	if err != nil {
+		err = translateError(err)
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("error collecting rows: %+v", err)
+		}
+		return nil, err
+	}
+
+	return &credentials, nil
+}
+
+// UpdateUser only writes the user if it is still at version, the upserted_at
+// it was read at, returning ErrNotFound otherwise.
+func (r *repository) UpdateUser(ctx context.Context, id int, name, handle, email, bio string, dob *model.Date, version time.Time) (*model.User, error) {
+	rows, err := r.db.Query(ctx, "update users set name = $1, handle = $2, email = $3, bio = $4, dob = $5 where id = $6 and upserted_at = $7 and deleted_at is null returning id, name, handle, email, upserted_at, bio, dob", name, handle, email, bio, dob, id, version)
+	if err != nil {
+		log.Printf("error updating user: %+v", err)
+		return nil, translateError(err)
+	}
+
+	user, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.User])
+	if err != nil {
+		err = translateError(err)
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("error updating user: %+v", err)
+		}
 		return nil, err
 	}
 
 	return &user, nil
 }
 
-func (r *repository) UpdateUser(id int, name, email, bio string, dob interface{}) error {
-	_, err := r.db.Exec(context.Background(), "update users set name = $1, email = $2, bio = $3, dob = $4 where id = $4", name, email, bio, dob, id)
+// DeleteUser deactivates the user. The row is kept, hidden from every other
+// read, until PurgeDeletedUsers removes it.
+func (r *repository) DeleteUser(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, "update users set deleted_at = now() where id = $1 and deleted_at is null", id)
 	if err != nil {
-		log.Printf("error inserting user: %+v", err)
+		log.Printf("error deleting user: %+v", err)
 		return err
 	}
 
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *repository) ReactivateUser(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, "update users set deleted_at = null where id = $1 and deleted_at is not null", id)
+	if err != nil {
+		log.Printf("error reactivating user: %+v", err)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeletedUsers hard-deletes the users deactivated before deletedBefore,
+// along with everything of theirs, returning how many were removed.
+func (r *repository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, "delete from users where deleted_at < $1", deletedBefore)
+	if err != nil {
+		log.Printf("error purging users: %+v", err)
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 	"x/pkg/model"
 	"x/pkg/util"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
@@ -26,27 +28,29 @@ func TestGetAllUsers_ReturnsUsers(t *testing.T) {
 		{
 			ID: 1,
 			Name: "user 1",
+			Handle: "user1",
 			Email: "email1",
 			UpsertedAt: dummyTime,
 			Bio: "bio1",
-			DOB: &dummyTime,
+			DOB: &model.Date{Time: dummyTime},
 		},
 		{
 			ID: 2,
 			Name: "user 2",
+			Handle: "user2",
 			Email: "email2",
 			UpsertedAt: dummyTime,
 			Bio: "bio2",
-			DOB: &dummyTime,
+			DOB: &model.Date{Time: dummyTime},
 		},
 	}
 
-	mockRows := mockDb.NewRows([]string{"id", "name", "email", "upserted_at", "bio", "dob"}).AddRow(1, "user 1", "email1", dummyTime, "bio1", &dummyTime).AddRow(2, "user 2", "email2", dummyTime, "bio2", &dummyTime)
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(1, "user 1", "user1", "email1", dummyTime, "bio1", &model.Date{Time: dummyTime}).AddRow(2, "user 2", "user2", "email2", dummyTime, "bio2", &model.Date{Time: dummyTime})
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WillReturnRows(mockRows)
+	mockDb.ExpectQuery("select id, name, handle, email, upserted_at, bio, dob from users").WithArgs("", 10).WillReturnRows(mockRows)
 
 	// act
-	actual, err := repo.GetAllUsers()
+	actual, err := repo.GetAllUsers(context.Background(), model.UserQuery{Limit: 10})
 	if err != nil {
 		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
 	}
@@ -69,10 +73,10 @@ func TestGetAllUsersFails_ReturnsError(t *testing.T) {
 
 	repo := New(mockDb)
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WillReturnError(errors.New("test error"))
+	mockDb.ExpectQuery("select id, name, handle, email, upserted_at, bio, dob from users").WithArgs("", 10).WillReturnError(errors.New("test error"))
 
 	// act
-	_, err = repo.GetAllUsers()
+	_, err = repo.GetAllUsers(context.Background(), model.UserQuery{Limit: 10})
 
 	// assert
 	if err.Error() != "test error" {
@@ -97,12 +101,12 @@ func TestGetAllUsersParseError_ReturnsError(t *testing.T) {
 	
 	dummyTime := time.Now()
 
-	mockRows := mockDb.NewRows([]string{"id", "name", "email", "upserted_at", "bio", "dob"}).AddRow(1, 1, "email1", dummyTime, "bio", dummyTime).AddRow(2, 2, "email2", dummyTime, "bio", dummyTime)
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(1, 1, "user1", "email1", dummyTime, "bio", dummyTime).AddRow(2, 2, "user2", "email2", dummyTime, "bio", dummyTime)
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WillReturnRows(mockRows)
+	mockDb.ExpectQuery("select id, name, handle, email, upserted_at, bio, dob from users").WithArgs("", 10).WillReturnRows(mockRows)
 
 	// act
-	_, err = repo.GetAllUsers()
+	_, err = repo.GetAllUsers(context.Background(), model.UserQuery{Limit: 10})
 
 	// assert
 	if err == nil {
@@ -114,6 +118,82 @@ func TestGetAllUsersParseError_ReturnsError(t *testing.T) {
 	}
 }
 
+func TestGetAllUsersWithCursor_ReturnsUsers(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	dummyTime := time.Now()
+	expected := []model.User{
+		{
+			ID: 2,
+			Name: "user 2",
+			Handle: "user2",
+			Email: "email2",
+			UpsertedAt: dummyTime,
+			Bio: "bio2",
+		},
+	}
+
+	query := model.UserQuery{
+		NamePrefix: "us",
+		SortBy: model.SortByUpsertedAt,
+		Descending: true,
+		After: &model.UserCursor{SortBy: model.SortByUpsertedAt, Descending: true, UpsertedAt: dummyTime, ID: 3},
+		Limit: 11,
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(2, "user 2", "user2", "email2", dummyTime, "bio2", (*model.Date)(nil))
+
+	mockDb.ExpectQuery(`select id, name, handle, email, upserted_at, bio, dob from users where deleted_at is null and lower\(name\) like lower\(\$1\) \|\| '%' and \(upserted_at, id\) < \(\$2, \$3\) order by upserted_at desc, id desc limit \$4`).WithArgs("us", dummyTime, 3, 11).WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetAllUsers(context.Background(), query)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetAllUsers_EscapesPrefixWildcards(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"})
+
+	mockDb.ExpectQuery("select id, name, handle, email, upserted_at, bio, dob from users").WithArgs(`50\%\_off`, 10).WillReturnRows(mockRows)
+
+	// act
+	_, err = repo.GetAllUsers(context.Background(), model.UserQuery{NamePrefix: "50%_off", SortBy: model.SortByName, Limit: 10})
+
+	// assert
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestCreateUser_ReturnsNoError(t *testing.T) {
 	// arrange
 	mockDb, err := pgxmock.NewPool()
@@ -125,12 +205,12 @@ func TestCreateUser_ReturnsNoError(t *testing.T) {
 
 	repo := New(mockDb)
 
-	dummyTime := time.Now()
+	dummyDate := model.Date{Time: time.Now()}
 
-	mockDb.ExpectExec("insert into users").WithArgs("Varun Gupta", "email1", "bio1", dummyTime).WillReturnResult(pgxmock.NewResult("", 1))
+	mockDb.ExpectExec("insert into users").WithArgs("Varun Gupta", "varun", "email1", "bio1", &dummyDate, "hash").WillReturnResult(pgxmock.NewResult("", 1))
 
 	// act
-	actual := repo.CreateUser("Varun Gupta", "email1", "bio1", dummyTime)
+	actual := repo.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", &dummyDate, "hash")
 	if actual != nil {
 		t.Errorf("expected: %+v, actual: %+v, error: %+v", nil, actual, err)
 	}
@@ -152,14 +232,14 @@ func TestCreateUser_ReturnsError(t *testing.T) {
 
 	repo := New(mockDb)
 
-	dummyTime := time.Now()
+	dummyDate := model.Date{Time: time.Now()}
 
 	expected := errors.New("test error")
 
-	mockDb.ExpectExec("insert into users").WithArgs("Varun Gupta", "email1", "bio1", dummyTime).WillReturnError(expected)
+	mockDb.ExpectExec("insert into users").WithArgs("Varun Gupta", "varun", "email1", "bio1", &dummyDate, "hash").WillReturnError(expected)
 
 	// act
-	actual := repo.CreateUser("Varun Gupta", "email1", "bio1", dummyTime)
+	actual := repo.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", &dummyDate, "hash")
 	if actual != expected {
 		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
 	}
@@ -185,18 +265,19 @@ func TestGetUser_ReturnsUser(t *testing.T) {
 	expected := model.User{
 			ID: 1,
 			Name: "user 1",
+			Handle: "user1",
 			Email: "email1",
 			UpsertedAt: dummyTime,
 			Bio: "bio1",
-			DOB: &dummyTime,
+			DOB: &model.Date{Time: dummyTime},
 	}
 
-	mockRows := mockDb.NewRows([]string{"id", "name", "email", "upserted_at", "bio", "dob"}).AddRow(1, "user 1", "email1", dummyTime, "bio1", &dummyTime)
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(1, "user 1", "user1", "email1", dummyTime, "bio1", &model.Date{Time: dummyTime})
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WithArgs(1).WillReturnRows(mockRows)
+	mockDb.ExpectQuery("select id, name, handle, email, upserted_at, bio, dob from users").WithArgs(1).WillReturnRows(mockRows)
 
 	// act
-	actual, err := repo.GetUser(1)
+	actual, err := repo.GetUser(context.Background(), 1)
 	if err != nil {
 		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
 	}
@@ -219,10 +300,10 @@ func TestGetUserFails_ReturnsError(t *testing.T) {
 
 	repo := New(mockDb)
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WithArgs(1).WillReturnError(errors.New("test error"))
+	mockDb.ExpectQuery("select id, name, handle, email, upserted_at, bio, dob from users").WithArgs(1).WillReturnError(errors.New("test error"))
 
 	// act
-	_, err = repo.GetUser(1)
+	_, err = repo.GetUser(context.Background(), 1)
 
 	// assert
 	if err.Error() != "test error" {
@@ -247,12 +328,12 @@ func TestGetUserParseError_ReturnsError(t *testing.T) {
 	
 	dummyTime := time.Now()
 
-	mockRows := mockDb.NewRows([]string{"id", "name", "email", "upserted_at", "bio", "dob"}).AddRow(1, 1, "email1", dummyTime, "bio", dummyTime)
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(1, 1, "user1", "email1", dummyTime, "bio", dummyTime)
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WithArgs(1).WillReturnRows(mockRows)
+	mockDb.ExpectQuery("select id, name, handle, email, upserted_at, bio, dob from users").WithArgs(1).WillReturnRows(mockRows)
 
 	// act
-	_, err = repo.GetUser(1)
+	_, err = repo.GetUser(context.Background(), 1)
 
 	// assert
 	if err == nil {
@@ -264,7 +345,7 @@ func TestGetUserParseError_ReturnsError(t *testing.T) {
 	}
 }
 
-func TestUpdateUser_ReturnsNoError(t *testing.T) {
+func TestUpdateUser_ReturnsUpdatedUser(t *testing.T) {
 	// arrange
 	mockDb, err := pgxmock.NewPool()
 	if err != nil {
@@ -276,16 +357,29 @@ func TestUpdateUser_ReturnsNoError(t *testing.T) {
 	repo := New(mockDb)
 
 	dummyTime := time.Now()
+	dummyDate := model.Date{Time: dummyTime}
+	expected := &model.User{
+		ID: 1,
+		Name: "Varun Gupta",
+		Handle: "varun",
+		Email: "email1",
+		UpsertedAt: dummyTime,
+		Bio: "bio1",
+		DOB: &dummyDate,
+	}
+
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(1, "Varun Gupta", "varun", "email1", dummyTime, "bio1", &dummyDate)
 
-	mockDb.ExpectExec("update users").WithArgs("Varun Gupta", "email1", "bio1", dummyTime, 1).WillReturnResult(pgxmock.NewResult("", 1))
+	mockDb.ExpectQuery("update users").WithArgs("Varun Gupta", "varun", "email1", "bio1", &dummyDate, 1, dummyTime).WillReturnRows(mockRows)
 
 	// act
-	actual := repo.UpdateUser(1, "Varun Gupta", "email1", "bio1", dummyTime)
-	if actual != nil {
-		t.Errorf("expected: %+v, actual: %+v, error: %+v", nil, actual, err)
+	actual, err := repo.UpdateUser(context.Background(), 1, "Varun Gupta", "varun", "email1", "bio1", &dummyDate, dummyTime)
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
 	}
 
 	// assert
+	util.AssertJSON(actual, expected, t)
 	if err := mockDb.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
@@ -303,14 +397,15 @@ func TestUpdateUser_ReturnsError(t *testing.T) {
 	repo := New(mockDb)
 
 	dummyTime := time.Now()
+	dummyDate := model.Date{Time: dummyTime}
 
 	expected := errors.New("test error")
 
-	mockDb.ExpectExec("update users").WithArgs("Varun Gupta", "email1", "bio1", dummyTime, 1).WillReturnError(expected)
+	mockDb.ExpectQuery("update users").WithArgs("Varun Gupta", "varun", "email1", "bio1", &dummyDate, 1, dummyTime).WillReturnError(expected)
 
 	// act
-	actual := repo.UpdateUser(1, "Varun Gupta", "email1", "bio1", dummyTime)
-	if actual != expected {
+	actual, err := repo.UpdateUser(context.Background(), 1, "Varun Gupta", "varun", "email1", "bio1", &dummyDate, dummyTime)
+	if err != expected {
 		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
 	}
 
@@ -320,11 +415,11 @@ func TestUpdateUser_ReturnsError(t *testing.T) {
 	}
 }
 
-func TestGetUserByEmail_ReturnsUser(t *testing.T) {
+func TestGetUserByHandle_ReturnsUser(t *testing.T) {
 	// arrange
 	mockDb, err := pgxmock.NewPool()
 	if err != nil {
-		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
 	defer mockDb.Close()
@@ -335,18 +430,18 @@ func TestGetUserByEmail_ReturnsUser(t *testing.T) {
 	expected := model.User{
 			ID: 1,
 			Name: "user 1",
+			Handle: "user1",
 			Email: "email1",
 			UpsertedAt: dummyTime,
 			Bio: "bio1",
-			DOB: &dummyTime,
 	}
 
-	mockRows := mockDb.NewRows([]string{"id", "name", "email", "upserted_at", "bio", "dob"}).AddRow(1, "user 1", "email1", dummyTime, "bio1", &dummyTime)
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"}).AddRow(1, "user 1", "user1", "email1", dummyTime, "bio1", (*model.Date)(nil))
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WithArgs("email1").WillReturnRows(mockRows)
+	mockDb.ExpectQuery(`select id, name, handle, email, upserted_at, bio, dob from users where lower\(handle\) = lower\(\$1\)`).WithArgs("User1").WillReturnRows(mockRows)
 
 	// act
-	actual, err := repo.GetUserByEmail("email1")
+	actual, err := repo.GetUserByHandle(context.Background(), "User1")
 	if err != nil {
 		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
 	}
@@ -358,25 +453,31 @@ func TestGetUserByEmail_ReturnsUser(t *testing.T) {
 	}
 }
 
-func TestGetUserByEmailFails_ReturnsError(t *testing.T) {
+func TestGetUserByHandleNoUser_ReturnsNotFound(t *testing.T) {
 	// arrange
 	mockDb, err := pgxmock.NewPool()
 	if err != nil {
-		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
 	defer mockDb.Close()
 
 	repo := New(mockDb)
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WithArgs("email1").WillReturnError(errors.New("test error"))
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"})
+
+	mockDb.ExpectQuery("select id, name, handle, email, upserted_at, bio, dob from users").WithArgs("user1").WillReturnRows(mockRows)
 
 	// act
-	_, err = repo.GetUserByEmail("email1")
+	actual, err := repo.GetUserByHandle(context.Background(), "user1")
 
 	// assert
-	if err.Error() != "test error" {
-		t.Errorf("expected: %+v, actual: %+v", "test error", err.Error())
+	if actual != nil {
+		t.Errorf("expected nil user")
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
 	}
 
 	if err := mockDb.ExpectationsWereMet(); err != nil {
@@ -384,7 +485,7 @@ func TestGetUserByEmailFails_ReturnsError(t *testing.T) {
 	}
 }
 
-func TestGetUserByEmailNoUser_ReturnsNilUserAndError(t *testing.T) {
+func TestHandleExists_ReturnsTrue(t *testing.T) {
 	// arrange
 	mockDb, err := pgxmock.NewPool()
 	if err != nil {
@@ -394,21 +495,110 @@ func TestGetUserByEmailNoUser_ReturnsNilUserAndError(t *testing.T) {
 	defer mockDb.Close()
 
 	repo := New(mockDb)
-	
-	mockRows := mockDb.NewRows([]string{"id", "name", "email", "upserted_at", "bio", "dob"})
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WithArgs("email1").WillReturnRows(mockRows)
+	mockRows := mockDb.NewRows([]string{"exists"}).AddRow(true)
+
+	mockDb.ExpectQuery(`select exists \(select 1 from users where lower\(handle\) = lower\(\$1\)\)`).WithArgs("user1").WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.HandleExists(context.Background(), "user1")
+
+	// assert
+	if err != nil || !actual {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", true, actual, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetCredentials_ReturnsCredentials(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	expected := model.Credentials{ID: 1, PasswordHash: "hash"}
+
+	mockRows := mockDb.NewRows([]string{"id", "password_hash", "deleted_at"}).AddRow(1, "hash", (*time.Time)(nil))
+
+	mockDb.ExpectQuery("select id, coalesce\\(password_hash, ''\\) as password_hash, deleted_at from users").WithArgs("email1").WillReturnRows(mockRows)
+
+	// act
+	actual, err := repo.GetCredentials(context.Background(), "email1")
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
+	}
+
+	// assert
+	util.AssertJSON(actual, expected, t)
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetCredentialsNoUser_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockRows := mockDb.NewRows([]string{"id", "password_hash", "deleted_at"})
+
+	mockDb.ExpectQuery("select id, (.+) from users").WithArgs("email1").WillReturnRows(mockRows)
 
 	// act
-	actual, err := repo.GetUserByEmail("email1")
+	actual, err := repo.GetCredentials(context.Background(), "email1")
 
 	// assert
 	if actual != nil {
-		t.Errorf("expected nil user")
+		t.Errorf("expected nil credentials")
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
+}
 
+func TestCreateUserDuplicateEmail_ReturnsConflict(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
 	if err != nil {
-		t.Errorf("expected nil error")
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("insert into users").WithArgs("Varun Gupta", "varun", "email1", "bio1", (*model.Date)(nil), "hash").WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})
+
+	// act
+	actual := repo.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", nil, "hash")
+
+	// assert
+	var conflict *ConflictError
+	if !errors.As(actual, &conflict) || !errors.Is(actual, ErrConflict) {
+		t.Fatalf("expected: %+v, actual: %+v", ErrConflict, actual)
+	}
+
+	if conflict.Constraint != "users_email_key" {
+		t.Errorf("expected: %+v, actual: %+v", "users_email_key", conflict.Constraint)
 	}
 
 	if err := mockDb.ExpectationsWereMet(); err != nil {
@@ -416,7 +606,7 @@ func TestGetUserByEmailNoUser_ReturnsNilUserAndError(t *testing.T) {
 	}
 }
 
-func TestGetUserByEmailParseError_ReturnsError(t *testing.T) {
+func TestUpdateUserStaleVersion_ReturnsNotFound(t *testing.T) {
 	// arrange
 	mockDb, err := pgxmock.NewPool()
 	if err != nil {
@@ -426,22 +616,123 @@ func TestGetUserByEmailParseError_ReturnsError(t *testing.T) {
 	defer mockDb.Close()
 
 	repo := New(mockDb)
-	
+
 	dummyTime := time.Now()
+	mockRows := mockDb.NewRows([]string{"id", "name", "handle", "email", "upserted_at", "bio", "dob"})
+
+	mockDb.ExpectQuery(`update users .* where id = \$6 and upserted_at = \$7`).WithArgs("Varun Gupta", "varun", "email1", "bio1", (*model.Date)(nil), 1, dummyTime).WillReturnRows(mockRows)
+
+	// act
+	_, err = repo.UpdateUser(context.Background(), 1, "Varun Gupta", "varun", "email1", "bio1", nil, dummyTime)
+
+	// assert
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
+	}
+
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
 
-	mockRows := mockDb.NewRows([]string{"id", "name", "email", "upserted_at", "bio", "dob"}).AddRow(1, 1, "email1", dummyTime, "bio", dummyTime)
+func TestDeleteUser_SoftDeletes(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
 
-	mockDb.ExpectQuery("select id, name, email, upserted_at, bio, dob from users").WithArgs("email1").WillReturnRows(mockRows)
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("update users set deleted_at = now\\(\\) where id = \\$1 and deleted_at is null").WithArgs(1).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 	// act
-	_, err = repo.GetUserByEmail("email1")
+	err = repo.DeleteUser(context.Background(), 1)
 
 	// assert
-	if err == nil {
-		t.Errorf("expected an error but nil was returned")
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteUserNoUser_ReturnsNotFound(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("update users set deleted_at").WithArgs(1).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	// act
+	err = repo.DeleteUser(context.Background(), 1)
+
+	// assert
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected: %+v, actual: %+v", ErrNotFound, err)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReactivateUser_ClearsDeletedAt(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	mockDb.ExpectExec("update users set deleted_at = null").WithArgs(1).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	// act
+	err = repo.ReactivateUser(context.Background(), 1)
+
+	// assert
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+	if err := mockDb.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPurgeDeletedUsers_ReturnsCount(t *testing.T) {
+	// arrange
+	mockDb, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
+	defer mockDb.Close()
+
+	repo := New(mockDb)
+
+	cutoff := time.Now()
+
+	mockDb.ExpectExec("delete from users where deleted_at < \\$1").WithArgs(cutoff).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+
+	// act
+	actual, err := repo.PurgeDeletedUsers(context.Background(), cutoff)
+
+	// assert
+	if err != nil || actual != 2 {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", 2, actual, err)
+	}
 	if err := mockDb.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,131 @@
+// Package search finds users and posts by the words they contain.
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"x/pkg/model"
+	"x/pkg/post"
+	"x/pkg/repository"
+)
+
+// MaxQueryLength is the maximum number of characters allowed in a query.
+const MaxQueryLength = 100
+
+var (
+	ErrEmptyQuery   = errors.New("search query must contain a letter or a digit")
+	ErrQueryTooLong = fmt.Errorf("search query cannot be longer than %d characters", MaxQueryLength)
+)
+
+type Service interface {
+	SearchUsers(ctx context.Context, query, cursor string, limit int) (*model.Page[model.User], error)
+	SearchPosts(ctx context.Context, viewerID int, query, cursor string, limit int) (*model.Page[model.Post], error)
+}
+
+type service struct {
+	db repository.SearchRepository
+	posts post.Service
+}
+
+// New returns a search Service. posts adds likes and reposted posts to the
+// posts found.
+func New(db repository.SearchRepository, posts post.Service) Service {
+	return &service{
+		db: db,
+		posts: posts,
+	}
+}
+
+// SearchUsers finds users by handle or name. Every word of query matches the
+// start of a word, so results can be shown as the query is typed.
+func (s *service) SearchUsers(ctx context.Context, query, cursor string, limit int) (*model.Page[model.User], error) {
+	words, err := validatedWords(query)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := model.ParseSearchCursor(cursor, model.SearchUsers, query)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = model.PageLimit(limit)
+
+	users, err := model.FetchPage(limit, func(limit int) ([]model.SearchedUser, error) {
+		return s.db.SearchUsers(ctx, prefixQuery(words), after, limit)
+	}, func(last model.SearchedUser) string {
+		return model.SearchCursor{Type: model.SearchUsers, Query: query, Rank: last.Rank, ID: last.ID}.String()
+	})
+	if err != nil {
+		log.Printf("error searching users: %+v", err)
+		return nil, err
+	}
+
+	return model.MapPage(users, func(user model.SearchedUser) model.User { return user.User }), nil
+}
+
+// SearchPosts finds posts by their text. query may quote phrases, use "or"
+// and exclude words with a leading "-", as in web search engines. viewerID is
+// 0 for anonymous readers, who are not told whether they like the posts.
+func (s *service) SearchPosts(ctx context.Context, viewerID int, query, cursor string, limit int) (*model.Page[model.Post], error) {
+	if _, err := validatedWords(query); err != nil {
+		return nil, err
+	}
+
+	after, err := model.ParseSearchCursor(cursor, model.SearchPosts, query)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = model.PageLimit(limit)
+
+	posts, err := model.FetchPage(limit, func(limit int) ([]model.SearchedPost, error) {
+		return s.db.SearchPosts(ctx, query, after, limit)
+	}, func(last model.SearchedPost) string {
+		return model.SearchCursor{Type: model.SearchPosts, Query: query, Rank: last.Rank, ID: last.ID}.String()
+	})
+	if err != nil {
+		log.Printf("error searching posts: %+v", err)
+		return nil, err
+	}
+
+	page := model.MapPage(posts, func(post model.SearchedPost) model.Post { return post.Post })
+	if err := s.posts.Decorate(ctx, viewerID, model.PostPointers(page.Items)); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// validatedWords returns the words of query, lower case. Anything but letters
+// and digits separates words, as it does in the search columns.
+func validatedWords(query string) ([]string, error) {
+	if utf8.RuneCountInString(query) > MaxQueryLength {
+		return nil, ErrQueryTooLong
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	return words, nil
+}
+
+// prefixQuery returns a tsquery matching the text that has a word starting with
+// each of words. Words are only letters and digits, so they need no quoting.
+func prefixQuery(words []string) string {
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"x/pkg/model"
+	"x/pkg/post"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRepo struct {
+	mock.Mock
+}
+
+func (m *mockRepo) SearchUsers(ctx context.Context, query string, after *model.SearchCursor, limit int) ([]model.SearchedUser, error) {
+	args := m.Called(query, after, limit)
+
+	return args.Get(0).([]model.SearchedUser), args.Error(1)
+}
+
+func (m *mockRepo) SearchPosts(ctx context.Context, query string, after *model.SearchCursor, limit int) ([]model.SearchedPost, error) {
+	args := m.Called(query, after, limit)
+
+	return args.Get(0).([]model.SearchedPost), args.Error(1)
+}
+
+// mockPosts only implements Decorate, the rest of post.Service is left nil.
+type mockPosts struct {
+	post.Service
+	mock.Mock
+}
+
+func (m *mockPosts) Decorate(ctx context.Context, viewerID int, posts []*model.Post) error {
+	args := m.Called(viewerID, posts)
+
+	return args.Error(0)
+}
+
+func TestSearchUsers_MatchesPrefixes(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, &mockPosts{})
+
+	users := []model.SearchedUser{{User: model.User{ID: 2, Handle: "michael_scott"}, Rank: 0.6}}
+
+	mockRepo.On("SearchUsers", "mich:* & sc:*", (*model.SearchCursor)(nil), 21).Return(users, nil)
+
+	actual, err := service.SearchUsers(context.Background(), "@Mich Sc", "", 0)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if len(actual.Items) != 1 || actual.Items[0].ID != 2 || actual.NextCursor != "" {
+		t.Errorf("expected one user and no next page, actual: %+v", actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchPosts_ReturnsPageWithNextCursor(t *testing.T) {
+	mockRepo := &mockRepo{}
+	mockPosts := &mockPosts{}
+	service := New(mockRepo, mockPosts)
+
+	posts := []model.SearchedPost{
+		{Post: model.Post{ID: 7}, Rank: 0.5},
+		{Post: model.Post{ID: 3}, Rank: 0.2},
+		{Post: model.Post{ID: 9}, Rank: 0.1},
+	}
+
+	mockRepo.On("SearchPosts", "hello", (*model.SearchCursor)(nil), 3).Return(posts, nil)
+	mockPosts.On("Decorate", 1, mock.AnythingOfType("[]*model.Post")).Return(nil)
+
+	actual, err := service.SearchPosts(context.Background(), 1, "hello", "", 2)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if len(actual.Items) != 2 {
+		t.Fatalf("expected 2 posts, actual: %+v", actual.Items)
+	}
+
+	next, err := model.ParseSearchCursor(actual.NextCursor, model.SearchPosts, "hello")
+	if err != nil {
+		t.Fatalf("expected a valid next cursor, actual: %+v", err)
+	}
+
+	if next.ID != 3 || next.Rank != 0.2 {
+		t.Errorf("expected cursor at post 3, actual: %+v", next)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockPosts.AssertExpectations(t)
+}
+
+func TestSearchPosts_CursorOfAnotherQuery_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, &mockPosts{})
+
+	cursor := model.SearchCursor{Type: model.SearchPosts, Query: "hello", Rank: 0.2, ID: 3}.String()
+
+	_, actual := service.SearchPosts(context.Background(), 1, "goodbye", cursor, 10)
+	if !errors.Is(actual, model.ErrInvalidCursor) {
+		t.Errorf("expected: %+v, actual: %+v", model.ErrInvalidCursor, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "SearchPosts", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSearch_InvalidQuery_ReturnsError(t *testing.T) {
+	cases := map[string]error{
+		"":                                    ErrEmptyQuery,
+		" #@! ":                               ErrEmptyQuery,
+		strings.Repeat("a", MaxQueryLength+1): ErrQueryTooLong,
+	}
+
+	for query, expected := range cases {
+		mockRepo := &mockRepo{}
+		service := New(mockRepo, &mockPosts{})
+
+		if _, actual := service.SearchUsers(context.Background(), query, "", 10); !errors.Is(actual, expected) {
+			t.Errorf("users %q: expected: %+v, actual: %+v", query, expected, actual)
+		}
+
+		if _, actual := service.SearchPosts(context.Background(), 0, query, "", 10); !errors.Is(actual, expected) {
+			t.Errorf("posts %q: expected: %+v, actual: %+v", query, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package timeline
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"x/pkg/model"
+)
+
+type memoryStore struct {
+	mu        sync.RWMutex
+	timelines map[int][]model.Post
+}
+
+// NewMemoryStore returns a Store that keeps timelines in process memory. It is
+// not shared between server instances and is lost on restart.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		timelines: map[int][]model.Post{},
+	}
+}
+
+func (s *memoryStore) Push(ctx context.Context, userIDs []int, post model.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, userID := range userIDs {
+		s.insert(userID, post)
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Add(ctx context.Context, userID int, posts []model.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, post := range posts {
+		s.insert(userID, post)
+	}
+
+	return nil
+}
+
+// insert adds post to the timeline of userID in order, unless it is already
+// there. The caller must hold the write lock.
+func (s *memoryStore) insert(userID int, post model.Post) {
+	posts := s.timelines[userID]
+
+	i := sort.Search(len(posts), func(i int) bool {
+		return !newerThan(posts[i], post.CreatedAt, post.ID)
+	})
+	if i < len(posts) && posts[i].ID == post.ID {
+		return
+	}
+
+	posts = append(posts, model.Post{})
+	copy(posts[i+1:], posts[i:])
+	posts[i] = post
+	s.timelines[userID] = posts
+}
+
+func (s *memoryStore) Get(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := s.timelines[userID]
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(posts), func(i int) bool {
+			return !newerThan(posts[i], after.CreatedAt, after.ID)
+		})
+		if start < len(posts) && posts[start].ID == after.ID && posts[start].CreatedAt.Equal(after.CreatedAt) {
+			start++
+		}
+	}
+
+	end := min(start+limit, len(posts))
+
+	return append([]model.Post{}, posts[start:end]...), nil
+}
+
+func (s *memoryStore) Remove(ctx context.Context, postID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userID, posts := range s.timelines {
+		s.timelines[userID] = slices.DeleteFunc(posts, func(post model.Post) bool {
+			return post.ID == postID || (post.RepostOfID != nil && *post.RepostOfID == postID)
+		})
+	}
+
+	return nil
+}
+
+func (s *memoryStore) RemoveAuthor(ctx context.Context, userID, authorID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	posts, ok := s.timelines[userID]
+	if !ok {
+		return nil
+	}
+
+	s.timelines[userID] = slices.DeleteFunc(posts, func(post model.Post) bool {
+		return post.AuthorID == authorID
+	})
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package timeline
+
+import (
+	"context"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/util"
+)
+
+func TestMemoryStore_GetReturnsNewestFirst(t *testing.T) {
+	store := NewMemoryStore()
+
+	now := time.Now()
+	older := model.Post{ID: 1, CreatedAt: now.Add(-time.Minute)}
+	newer := model.Post{ID: 2, CreatedAt: now}
+	sameTime := model.Post{ID: 3, CreatedAt: now}
+
+	store.Push(context.Background(), []int{1}, newer)
+	store.Push(context.Background(), []int{1}, older)
+	store.Push(context.Background(), []int{1}, sameTime)
+	store.Push(context.Background(), []int{1}, newer)
+
+	actual, _ := store.Get(context.Background(), 1, nil, 10)
+	util.AssertJSON(actual, []model.Post{sameTime, newer, older}, t)
+}
+
+func TestMemoryStore_GetPagesAfterCursor(t *testing.T) {
+	store := NewMemoryStore()
+
+	now := time.Now()
+	posts := []model.Post{
+		{ID: 3, CreatedAt: now},
+		{ID: 2, CreatedAt: now.Add(-time.Minute)},
+		{ID: 1, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+
+	for _, post := range posts {
+		store.Push(context.Background(), []int{1}, post)
+	}
+
+	actual, _ := store.Get(context.Background(), 1, &model.Cursor{CreatedAt: posts[0].CreatedAt, ID: posts[0].ID}, 1)
+	util.AssertJSON(actual, posts[1:2], t)
+
+	actual, _ = store.Get(context.Background(), 1, &model.Cursor{CreatedAt: posts[1].CreatedAt, ID: posts[1].ID}, 10)
+	util.AssertJSON(actual, posts[2:], t)
+}
+
+func TestMemoryStore_RemoveDropsPostEverywhere(t *testing.T) {
+	store := NewMemoryStore()
+
+	post := model.Post{ID: 1, CreatedAt: time.Now()}
+	store.Push(context.Background(), []int{1, 2}, post)
+
+	store.Remove(context.Background(), 1)
+
+	for _, userID := range []int{1, 2} {
+		actual, _ := store.Get(context.Background(), userID, nil, 10)
+		if len(actual) != 0 {
+			t.Errorf("expected empty timeline for user %d, actual: %+v", userID, actual)
+		}
+	}
+}
+
+func TestMemoryStore_RemoveDropsReposts(t *testing.T) {
+	store := NewMemoryStore()
+
+	originalID := 1
+	now := time.Now()
+	store.Push(context.Background(), []int{1}, model.Post{ID: 1, CreatedAt: now})
+	store.Push(context.Background(), []int{1}, model.Post{ID: 2, CreatedAt: now.Add(time.Second), Kind: model.PostKindRepost, RepostOfID: &originalID})
+
+	store.Remove(context.Background(), 1)
+
+	actual, _ := store.Get(context.Background(), 1, nil, 10)
+	if len(actual) != 0 {
+		t.Errorf("expected empty timeline, actual: %+v", actual)
+	}
+}
+
+func TestMemoryStore_RemoveAuthorDropsTheirPosts(t *testing.T) {
+	store := NewMemoryStore()
+
+	now := time.Now()
+	kept := model.Post{ID: 1, AuthorID: 10, CreatedAt: now}
+	store.Add(context.Background(), 1, []model.Post{kept, {ID: 2, AuthorID: 20, CreatedAt: now.Add(time.Second)}})
+	store.Push(context.Background(), []int{2}, model.Post{ID: 2, AuthorID: 20, CreatedAt: now.Add(time.Second)})
+
+	store.RemoveAuthor(context.Background(), 1, 20)
+
+	actual, _ := store.Get(context.Background(), 1, nil, 10)
+	util.AssertJSON(actual, []model.Post{kept}, t)
+
+	actual, _ = store.Get(context.Background(), 2, nil, 10)
+	if len(actual) != 1 {
+		t.Errorf("expected other timelines to be left alone, actual: %+v", actual)
+	}
+}
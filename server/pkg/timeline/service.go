@@ -0,0 +1,171 @@
+package timeline
+
+import (
+	"context"
+	"log"
+	"time"
+	"x/pkg/model"
+	"x/pkg/repository"
+)
+
+// DefaultFanOutThreshold is the follower count above which an author's posts
+// are merged into timelines at read time instead of being pushed on write.
+const DefaultFanOutThreshold = 10000
+
+// backfillLimit is how many of the latest posts of an account are added to a
+// timeline when the account is followed.
+const backfillLimit = 100
+
+// Service builds home timelines with a hybrid strategy: posts by authors with
+// at most fanOutThreshold followers are pushed into every follower's timeline
+// in the Store when created, while posts by more followed authors are fetched
+// from the posts table when a timeline is read.
+type Service interface {
+	Publish(ctx context.Context, post model.Post) error
+	Retract(ctx context.Context, postID int) error
+	GetTimeline(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error)
+	// Follow adds the latest posts of followeeID to the timeline of followerID.
+	Follow(ctx context.Context, followerID, followeeID int) error
+	// Unfollow removes the posts of followeeID from the timeline of followerID.
+	Unfollow(ctx context.Context, followerID, followeeID int) error
+}
+
+type service struct {
+	db repository.Repository
+	store Store
+	fanOutThreshold int
+}
+
+func New(db repository.Repository, store Store, fanOutThreshold int) Service {
+	return &service{
+		db: db,
+		store: store,
+		fanOutThreshold: fanOutThreshold,
+	}
+}
+
+func (s *service) Publish(ctx context.Context, post model.Post) error {
+	readers := []int{post.AuthorID}
+
+	followers, err := s.db.CountFollowers(ctx, post.AuthorID)
+	if err != nil {
+		log.Printf("error counting followers: %+v", err)
+		return err
+	}
+
+	if followers <= s.fanOutThreshold {
+		followerIDs, err := s.db.GetFollowerIDs(ctx, post.AuthorID)
+		if err != nil {
+			log.Printf("error fetching follower ids: %+v", err)
+			return err
+		}
+
+		readers = append(readers, followerIDs...)
+	}
+
+	if err := s.store.Push(ctx, readers, post); err != nil {
+		log.Printf("error pushing post to timelines: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) Retract(ctx context.Context, postID int) error {
+	if err := s.store.Remove(ctx, postID); err != nil {
+		log.Printf("error removing post from timelines: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Follow backfills the timeline of followerID, unless the posts of followeeID
+// are merged in when timelines are read.
+func (s *service) Follow(ctx context.Context, followerID, followeeID int) error {
+	followers, err := s.db.CountFollowers(ctx, followeeID)
+	if err != nil {
+		log.Printf("error counting followers: %+v", err)
+		return err
+	}
+
+	if followers > s.fanOutThreshold {
+		return nil
+	}
+
+	posts, err := s.db.GetAuthorPosts(ctx, followeeID, backfillLimit)
+	if err != nil {
+		log.Printf("error fetching author posts: %+v", err)
+		return err
+	}
+
+	if err := s.store.Add(ctx, followerID, posts); err != nil {
+		log.Printf("error adding posts to timeline: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Unfollow removes the posts of followeeID whether or not they were fanned out,
+// since the account may have crossed the threshold since.
+func (s *service) Unfollow(ctx context.Context, followerID, followeeID int) error {
+	if err := s.store.RemoveAuthor(ctx, followerID, followeeID); err != nil {
+		log.Printf("error removing author from timeline: %+v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) GetTimeline(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error) {
+	pushed, err := s.store.Get(ctx, userID, after, limit)
+	if err != nil {
+		log.Printf("error fetching stored timeline: %+v", err)
+		return nil, err
+	}
+
+	pulled, err := s.db.GetPopularTimeline(ctx, userID, s.fanOutThreshold, after, limit)
+	if err != nil {
+		log.Printf("error fetching popular timeline: %+v", err)
+		return nil, err
+	}
+
+	return merge(pushed, pulled, limit), nil
+}
+
+// merge combines two newest-first post lists into one, dropping posts present
+// in both (an author may have crossed the fan-out threshold) and keeping at
+// most limit posts.
+func merge(a, b []model.Post, limit int) []model.Post {
+	merged := make([]model.Post, 0, min(len(a)+len(b), limit))
+	seen := map[int]bool{}
+
+	for len(merged) < limit && (len(a) > 0 || len(b) > 0) {
+		var next model.Post
+		if len(b) == 0 || (len(a) > 0 && newerThan(a[0], b[0].CreatedAt, b[0].ID)) {
+			next, a = a[0], a[1:]
+		} else {
+			next, b = b[0], b[1:]
+		}
+
+		if seen[next.ID] {
+			continue
+		}
+
+		seen[next.ID] = true
+		merged = append(merged, next)
+	}
+
+	return merged
+}
+
+// newerThan reports whether post sorts before the position (createdAt, id) in
+// a newest-first timeline.
+func newerThan(post model.Post, createdAt time.Time, id int) bool {
+	if post.CreatedAt.Equal(createdAt) {
+		return post.ID > id
+	}
+
+	return post.CreatedAt.After(createdAt)
+}
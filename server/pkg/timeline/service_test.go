@@ -0,0 +1,202 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+	"x/pkg/repository"
+	"x/pkg/util"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRepo struct {
+	repository.Repository
+	mock.Mock
+}
+
+func (m *mockRepo) CountFollowers(ctx context.Context, id int) (int, error) {
+	args := m.Called(id)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockRepo) GetFollowerIDs(ctx context.Context, id int) ([]int, error) {
+	args := m.Called(id)
+
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *mockRepo) GetPopularTimeline(ctx context.Context, userID, minFollowers int, after *model.Cursor, limit int) ([]model.Post, error) {
+	args := m.Called(userID, minFollowers, after, limit)
+
+	return args.Get(0).([]model.Post), args.Error(1)
+}
+
+func (m *mockRepo) GetAuthorPosts(ctx context.Context, authorID, limit int) ([]model.Post, error) {
+	args := m.Called(authorID, limit)
+
+	return args.Get(0).([]model.Post), args.Error(1)
+}
+
+func TestPublish_BelowThreshold_PushesToFollowers(t *testing.T) {
+	mockRepo := &mockRepo{}
+	store := NewMemoryStore()
+	service := New(mockRepo, store, 2)
+
+	post := model.Post{ID: 1, AuthorID: 10, Text: "hello", CreatedAt: time.Now()}
+
+	mockRepo.On("CountFollowers", 10).Return(2, nil)
+	mockRepo.On("GetFollowerIDs", 10).Return([]int{20, 30}, nil)
+
+	if err := service.Publish(context.Background(), post); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	for _, userID := range []int{10, 20, 30} {
+		actual, _ := store.Get(context.Background(), userID, nil, 10)
+		util.AssertJSON(actual, []model.Post{post}, t)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPublish_AboveThreshold_PushesToAuthorOnly(t *testing.T) {
+	mockRepo := &mockRepo{}
+	store := NewMemoryStore()
+	service := New(mockRepo, store, 2)
+
+	post := model.Post{ID: 1, AuthorID: 10, Text: "hello", CreatedAt: time.Now()}
+
+	mockRepo.On("CountFollowers", 10).Return(3, nil)
+
+	if err := service.Publish(context.Background(), post); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	actual, _ := store.Get(context.Background(), 10, nil, 10)
+	util.AssertJSON(actual, []model.Post{post}, t)
+
+	actual, _ = store.Get(context.Background(), 20, nil, 10)
+	if len(actual) != 0 {
+		t.Errorf("expected follower timeline to be empty, actual: %+v", actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetFollowerIDs", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPublish_CountFails_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, NewMemoryStore(), 2)
+
+	expected := errors.New("test error")
+
+	mockRepo.On("CountFollowers", 10).Return(0, expected)
+
+	actual := service.Publish(context.Background(), model.Post{ID: 1, AuthorID: 10})
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTimeline_MergesPushedAndPulledPosts(t *testing.T) {
+	mockRepo := &mockRepo{}
+	store := NewMemoryStore()
+	service := New(mockRepo, store, 2)
+
+	now := time.Now()
+	pushed := []model.Post{
+		{ID: 4, AuthorID: 10, CreatedAt: now},
+		{ID: 2, AuthorID: 10, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	pulled := []model.Post{
+		{ID: 3, AuthorID: 11, CreatedAt: now.Add(-time.Minute)},
+		{ID: 2, AuthorID: 10, CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: 1, AuthorID: 11, CreatedAt: now.Add(-3 * time.Minute)},
+	}
+
+	for _, post := range pushed {
+		store.Push(context.Background(), []int{20}, post)
+	}
+
+	mockRepo.On("GetPopularTimeline", 20, 2, (*model.Cursor)(nil), 3).Return(pulled, nil)
+
+	actual, err := service.GetTimeline(context.Background(), 20, nil, 3)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual, []model.Post{pushed[0], pulled[0], pushed[1]}, t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTimeline_PullFails_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, NewMemoryStore(), 2)
+
+	expected := errors.New("test error")
+
+	mockRepo.On("GetPopularTimeline", 20, 2, (*model.Cursor)(nil), 3).Return(([]model.Post)(nil), expected)
+
+	_, actual := service.GetTimeline(context.Background(), 20, nil, 3)
+	if actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFollow_BelowThreshold_BackfillsTimeline(t *testing.T) {
+	mockRepo := &mockRepo{}
+	store := NewMemoryStore()
+	service := New(mockRepo, store, 2)
+
+	posts := []model.Post{{ID: 2, AuthorID: 10, CreatedAt: time.Now()}, {ID: 1, AuthorID: 10, CreatedAt: time.Now().Add(-time.Hour)}}
+
+	mockRepo.On("CountFollowers", 10).Return(2, nil)
+	mockRepo.On("GetAuthorPosts", 10, backfillLimit).Return(posts, nil)
+
+	if err := service.Follow(context.Background(), 20, 10); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	actual, _ := store.Get(context.Background(), 20, nil, 10)
+	util.AssertJSON(actual, posts, t)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFollow_AboveThreshold_LeavesTimeline(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, NewMemoryStore(), 2)
+
+	mockRepo.On("CountFollowers", 10).Return(3, nil)
+
+	if err := service.Follow(context.Background(), 20, 10); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetAuthorPosts", mock.Anything, mock.Anything)
+}
+
+func TestUnfollow_RemovesAuthorPosts(t *testing.T) {
+	mockRepo := &mockRepo{}
+	store := NewMemoryStore()
+	service := New(mockRepo, store, 2)
+
+	store.Push(context.Background(), []int{20}, model.Post{ID: 1, AuthorID: 10, CreatedAt: time.Now()})
+
+	if err := service.Unfollow(context.Background(), 20, 10); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	actual, _ := store.Get(context.Background(), 20, nil, 10)
+	if len(actual) != 0 {
+		t.Errorf("expected empty timeline, actual: %+v", actual)
+	}
+}
@@ -0,0 +1,51 @@
+package timeline
+
+import (
+	"context"
+	"x/pkg/model"
+	"x/pkg/repository"
+)
+
+// Store holds precomputed home timelines. Implementations must return posts
+// newest first, ordered by creation time and then id. Removing a post also
+// removes its reposts, as deleting a post deletes them.
+type Store interface {
+	Push(ctx context.Context, userIDs []int, post model.Post) error
+	Get(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error)
+	Remove(ctx context.Context, postID int) error
+	// Add adds posts to the timeline of userID.
+	Add(ctx context.Context, userID int, posts []model.Post) error
+	// RemoveAuthor removes the posts of authorID from the timeline of userID.
+	RemoveAuthor(ctx context.Context, userID, authorID int) error
+}
+
+type postgresStore struct {
+	db repository.TimelineRepository
+}
+
+// NewPostgresStore returns a Store backed by the timeline_entries table.
+func NewPostgresStore(db repository.TimelineRepository) Store {
+	return &postgresStore{
+		db: db,
+	}
+}
+
+func (s *postgresStore) Push(ctx context.Context, userIDs []int, post model.Post) error {
+	return s.db.PushTimelineEntries(ctx, userIDs, post)
+}
+
+func (s *postgresStore) Get(ctx context.Context, userID int, after *model.Cursor, limit int) ([]model.Post, error) {
+	return s.db.GetTimelineEntries(ctx, userID, after, limit)
+}
+
+func (s *postgresStore) Remove(ctx context.Context, postID int) error {
+	return s.db.DeleteTimelineEntries(ctx, postID)
+}
+
+func (s *postgresStore) Add(ctx context.Context, userID int, posts []model.Post) error {
+	return s.db.AddTimelineEntries(ctx, userID, posts)
+}
+
+func (s *postgresStore) RemoveAuthor(ctx context.Context, userID, authorID int) error {
+	return s.db.DeleteAuthorTimelineEntries(ctx, userID, authorID)
+}
@@ -0,0 +1,120 @@
+// Package trend finds the hashtags used the most lately.
+package trend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"x/pkg/model"
+	"x/pkg/repository"
+)
+
+// DefaultWindows are the windows trends are computed over when none are
+// configured.
+const DefaultWindows = "1h,24h"
+
+// Count is the number of trends kept for each window.
+const Count = 10
+
+var ErrUnknownWindow = errors.New("unknown trends window")
+
+// Window is a span of time trends are computed over, named after its length
+// as configured, such as "1h". Posts weigh half as much every HalfLife, a
+// quarter of the window, so the score favours hashtags gaining momentum over
+// those that were popular at the start of the window.
+type Window struct {
+	Name string
+	Length time.Duration
+	HalfLife time.Duration
+}
+
+// ParseWindows reads a comma separated list of window lengths such as
+// "1h,24h". The first window is the one served by default.
+func ParseWindows(s string) ([]Window, error) {
+	var windows []Window
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		length, err := time.ParseDuration(name)
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("invalid trends window %q", name)
+		}
+		windows = append(windows, Window{Name: name, Length: length, HalfLife: length / 4})
+	}
+
+	return windows, nil
+}
+
+// Service computes trends in the background and serves the last ones computed,
+// so that reading them never queries the database.
+type Service interface {
+	// Refresh computes the trends of every window and replaces those served.
+	Refresh(ctx context.Context) error
+	// GetTrends returns the trends of the named window, or of the default one
+	// when window is empty. They are empty until the first Refresh.
+	GetTrends(window string) (*model.Trends, error)
+}
+
+type service struct {
+	db repository.TrendRepository
+	windows []Window
+
+	mu sync.RWMutex
+	trends map[string]*model.Trends
+}
+
+// New returns a trend Service computing trends over windows, which must not be
+// empty.
+func New(db repository.TrendRepository, windows []Window) Service {
+	trends := make(map[string]*model.Trends, len(windows))
+	for _, window := range windows {
+		trends[window.Name] = &model.Trends{Window: window.Name, Trends: []model.Trend{}}
+	}
+
+	return &service{
+		db: db,
+		windows: windows,
+		trends: trends,
+	}
+}
+
+// Refresh computes every window as of the same instant. The trends served are
+// left as they were when any window fails.
+func (s *service) Refresh(ctx context.Context) error {
+	now := time.Now()
+
+	trends := make(map[string]*model.Trends, len(s.windows))
+	for _, window := range s.windows {
+		top, err := s.db.GetTrends(ctx, now, window.Length, window.HalfLife, Count)
+		if err != nil {
+			log.Printf("error fetching trends: %+v", err)
+			return err
+		}
+		trends[window.Name] = &model.Trends{Window: window.Name, Trends: top, UpdatedAt: now}
+	}
+
+	s.mu.Lock()
+	s.trends = trends
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *service) GetTrends(window string) (*model.Trends, error) {
+	if window == "" {
+		window = s.windows[0].Name
+	}
+
+	s.mu.RLock()
+	trends, ok := s.trends[window]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownWindow
+	}
+
+	return trends, nil
+}
@@ -0,0 +1,119 @@
+package trend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"x/pkg/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRepo struct {
+	mock.Mock
+}
+
+func (m *mockRepo) GetTrends(ctx context.Context, now time.Time, window, halfLife time.Duration, limit int) ([]model.Trend, error) {
+	args := m.Called(window, halfLife, limit)
+
+	return args.Get(0).([]model.Trend), args.Error(1)
+}
+
+func TestParseWindows_ReturnsWindows(t *testing.T) {
+	actual, err := ParseWindows(DefaultWindows)
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	expected := []Window{
+		{Name: "1h", Length: time.Hour, HalfLife: 15 * time.Minute},
+		{Name: "24h", Length: 24 * time.Hour, HalfLife: 6 * time.Hour},
+	}
+	if len(actual) != len(expected) || actual[0] != expected[0] || actual[1] != expected[1] {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+}
+
+func TestParseWindows_Invalid_ReturnsError(t *testing.T) {
+	for _, s := range []string{"", "1h,", "soon", "-1h"} {
+		if _, err := ParseWindows(s); err == nil {
+			t.Errorf("windows %q: expected an error", s)
+		}
+	}
+}
+
+func TestGetTrends_BeforeRefresh_ReturnsNoTrends(t *testing.T) {
+	mockRepo := &mockRepo{}
+	windows, _ := ParseWindows(DefaultWindows)
+	service := New(mockRepo, windows)
+
+	actual, err := service.GetTrends("")
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if actual.Window != "1h" || len(actual.Trends) != 0 {
+		t.Errorf("expected no trends over 1h, actual: %+v", actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetTrends", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_ServesTrendsOfEveryWindow(t *testing.T) {
+	mockRepo := &mockRepo{}
+	windows, _ := ParseWindows(DefaultWindows)
+	service := New(mockRepo, windows)
+
+	hour := []model.Trend{{Hashtag: "golang", PostCount: 3, Score: 2.5}}
+	day := []model.Trend{{Hashtag: "café", PostCount: 40, Score: 12}}
+
+	mockRepo.On("GetTrends", time.Hour, 15*time.Minute, Count).Return(hour, nil)
+	mockRepo.On("GetTrends", 24*time.Hour, 6*time.Hour, Count).Return(day, nil)
+
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	actual, err := service.GetTrends("24h")
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if actual.Window != "24h" || len(actual.Trends) != 1 || actual.Trends[0].Hashtag != "café" || actual.UpdatedAt.IsZero() {
+		t.Errorf("expected the trends over 24h, actual: %+v", actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_Fails_KeepsTrends(t *testing.T) {
+	mockRepo := &mockRepo{}
+	windows, _ := ParseWindows("1h")
+	service := New(mockRepo, windows)
+
+	expected := errors.New("test error")
+	mockRepo.On("GetTrends", time.Hour, 15*time.Minute, Count).Return([]model.Trend{{Hashtag: "golang"}}, nil).Once()
+	mockRepo.On("GetTrends", time.Hour, 15*time.Minute, Count).Return([]model.Trend(nil), expected).Once()
+
+	service.Refresh(context.Background())
+	if actual := service.Refresh(context.Background()); actual != expected {
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
+	}
+
+	actual, _ := service.GetTrends("1h")
+	if len(actual.Trends) != 1 {
+		t.Errorf("expected the trends of the first refresh, actual: %+v", actual)
+	}
+}
+
+func TestGetTrends_UnknownWindow_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	windows, _ := ParseWindows(DefaultWindows)
+	service := New(mockRepo, windows)
+
+	_, actual := service.GetTrends("7d")
+	if !errors.Is(actual, ErrUnknownWindow) {
+		t.Errorf("expected: %+v, actual: %+v", ErrUnknownWindow, actual)
+	}
+}
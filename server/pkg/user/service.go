@@ -1,109 +1,308 @@
 package user
 
 import (
+	"context"
+	"errors"
 	"log"
-	"strconv"
-	"strings"
 	"time"
 	"x/pkg/model"
 	"x/pkg/repository"
+	"x/pkg/validate"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrEmailTaken         = errors.New("email is already taken")
+	ErrHandleTaken        = errors.New("handle is already taken")
+	ErrVersionMismatch    = errors.New("user was modified since it was read")
+)
+
+// DefaultRetention is how long a deleted account can be reactivated by logging
+// in before it is purged for good.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// the unique constraints on users.email and lower(users.handle)
+const (
+	emailConstraint  = "users_email_key"
+	handleConstraint = "users_handle_key"
 )
 
+// dummyHash is compared against when no user matches a login attempt, so that
+// unknown emails take as long to reject as wrong passwords.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
 type Service interface {
-	GetAllUsers() ([]model.User, error)
-	GetUserByEmail(email string) (*model.User, error)
-	CreateUser(name, email, bio, dob string) error
-	UpdateUser(id int, name, email string, bio interface{}, dob string) error
+	GetAllUsers(ctx context.Context, params model.ListUsers) (*model.Page[model.User], error)
+	GetUserByHandle(ctx context.Context, handle string) (*model.User, error)
+	CheckHandle(ctx context.Context, handle string) (*model.HandleAvailability, error)
+	CreateUser(ctx context.Context, name, handle, email, bio, dob, password string) error
+	UpdateUser(ctx context.Context, id int, version time.Time, patch model.UpdateUser) (*model.User, error)
+	Login(ctx context.Context, email, password string) (*model.User, error)
+	DeleteUser(ctx context.Context, id int) error
+	IsActive(ctx context.Context, id int) (bool, error)
+	PurgeDeletedUsers(ctx context.Context) (int64, error)
 }
 
 type service struct {
-	db repository.Repository
+	db repository.UserRepository
+	retention time.Duration
 }
 
-func New(db repository.Repository) Service {
+// New returns a user service that keeps deleted accounts for retention before
+// they can be purged.
+func New(db repository.UserRepository, retention time.Duration) Service {
 	return &service{
 		db: db,
+		retention: retention,
 	}
 }
 
-func (s *service) GetAllUsers() ([]model.User, error) {
-	users, err := s.db.GetAllUsers()
+func (s *service) GetAllUsers(ctx context.Context, params model.ListUsers) (*model.Page[model.User], error) {
+	sortBy, descending, err := model.ParseUserSort(params.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := model.ParseUserCursor(params.Cursor, sortBy, descending)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := model.PageLimit(params.Limit)
+
+	page, err := model.FetchPage(limit, func(limit int) ([]model.User, error) {
+		return s.db.GetAllUsers(ctx, model.UserQuery{
+			NamePrefix: params.NamePrefix,
+			SortBy: sortBy,
+			Descending: descending,
+			After: after,
+			Limit: limit,
+		})
+	}, func(last model.User) string {
+		return model.NewUserCursor(last, sortBy, descending).String()
+	})
 	if err != nil {
 		log.Printf("error fetching users: %+v", err)
 		return nil, err
 	}
 
-	return users, nil
+	return page, nil
 }
 
-func (s *service) GetUserByEmail(email string) (*model.User, error) {
-	user, err := s.db.GetUserByEmail(email)
+func (s *service) GetUserByHandle(ctx context.Context, handle string) (*model.User, error) {
+	user, err := s.db.GetUserByHandle(ctx, handle)
 	if err != nil {
 		log.Printf("error fetching user: %+v", err)
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return user, nil
 }
 
-func (s *service) CreateUser(name, email, bio, dob string) error {
-	var validatedDob interface{}
-	if dob == "" {
-		validatedDob = nil
-	} else {
-		parsedDob := strings.Split(dob, "-")
-		day, _ := strconv.Atoi(parsedDob[0])
-		month, _ := strconv.Atoi(parsedDob[1])
-		year, _ := strconv.Atoi(parsedDob[2])
-		validatedDob = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+// CheckHandle tells whether handle could be registered right now: it must
+// follow the handle rules and nobody may hold it yet.
+func (s *service) CheckHandle(ctx context.Context, handle string) (*model.HandleAvailability, error) {
+	availability := &model.HandleAvailability{Handle: handle}
+
+	errs := validate.Errors{}
+	errs.Field("handle", handle, validate.Required, model.ValidHandle)
+	if err := errs.Err(); err != nil {
+		availability.Reason = err.Error()
+		return availability, nil
 	}
 
-	if err := s.db.CreateUser(name, email, bio, validatedDob); err != nil {
-		log.Printf("error creating user: %+v", err)
+	taken, err := s.db.HandleExists(ctx, handle)
+	if err != nil {
+		log.Printf("error checking handle: %+v", err)
+		return nil, err
+	}
+
+	if taken {
+		availability.Reason = ErrHandleTaken.Error()
+		return availability, nil
+	}
+
+	availability.Available = true
+	return availability, nil
+}
+
+func (s *service) CreateUser(ctx context.Context, name, handle, email, bio, dob, password string) error {
+	validatedDob, err := parseDOB(dob)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("error hashing password: %+v", err)
 		return err
 	}
 
+	if err := s.db.CreateUser(ctx, name, handle, email, bio, validatedDob, string(passwordHash)); err != nil {
+		log.Printf("error creating user: %+v", err)
+		return domainError(err)
+	}
+
 	return nil
 }
 
-func (s *service) UpdateUser(id int, name, email string, bio interface{}, dob string) error {
-	var validatedDob interface{}
-	if dob == "" {
-		validatedDob = nil
-	} else {
-		parsedDob := strings.Split(dob, "-")
-		day, _ := strconv.Atoi(parsedDob[0])
-		month, _ := strconv.Atoi(parsedDob[1])
-		year, _ := strconv.Atoi(parsedDob[2])
-		validatedDob = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+// UpdateUser applies a merge patch to the user's profile and returns the user
+// as stored afterwards. version is the upserted_at the client last saw; the
+// update fails with ErrVersionMismatch if the user changed since.
+func (s *service) UpdateUser(ctx context.Context, id int, version time.Time, patch model.UpdateUser) (*model.User, error) {
+	user, err := s.db.GetUser(ctx, id)
+	if err != nil {
+		log.Printf("error fetching user: %+v", err)
+		return nil, domainError(err)
+	}
+
+	if !user.UpsertedAt.Equal(version) {
+		return nil, ErrVersionMismatch
+	}
+
+	if patch.Name.Set {
+		user.Name = patch.Name.Value
+	}
+
+	if patch.Handle.Set {
+		user.Handle = patch.Handle.Value
+	}
+
+	if patch.Email.Set {
+		user.Email = patch.Email.Value
+	}
+
+	if patch.Bio.Set {
+		user.Bio = patch.Bio.Value
+	}
+
+	if patch.DOB.Set {
+		user.DOB, err = parseDOB(patch.DOB.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.db.UpdateUser(ctx, id, user.Name, user.Handle, user.Email, user.Bio, user.DOB, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		// the user was there a moment ago, so it was changed in between
+		return nil, ErrVersionMismatch
 	}
 
-	currentUser, err := s.db.GetUser(id)
 	if err != nil {
-		log.Printf("error fetching user: %+v", err)
-		return err
+		log.Printf("error updating user: %+v", err)
+		return nil, domainError(err)
 	}
 
-	if name == "" {
-		name = currentUser.Name
+	return updated, nil
+}
+
+func (s *service) Login(ctx context.Context, email, password string) (*model.User, error) {
+	credentials, err := s.db.GetCredentials(ctx, email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Printf("error fetching credentials: %+v", err)
+		return nil, err
 	}
 
-	if email == "" {
-		email = currentUser.Email
+	if credentials == nil || credentials.PasswordHash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
 	}
 
-	if bio == nil {
-		bio = currentUser.Bio
+	if err := bcrypt.CompareHashAndPassword([]byte(credentials.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
 	}
 
-	if validatedDob == nil {
-		validatedDob = currentUser.DOB
+	if credentials.DeletedAt != nil {
+		// logging in during the retention period reactivates the account
+		if time.Since(*credentials.DeletedAt) > s.retention {
+			return nil, ErrInvalidCredentials
+		}
+
+		if err := s.db.ReactivateUser(ctx, credentials.ID); err != nil {
+			log.Printf("error reactivating user: %+v", err)
+			return nil, domainError(err)
+		}
 	}
 
-	if err := s.db.UpdateUser(id, name, email, bio.(string), validatedDob); err != nil {
-		log.Printf("error creating user: %+v", err)
-		return err
+	user, err := s.db.GetUser(ctx, credentials.ID)
+	if err != nil {
+		log.Printf("error fetching user: %+v", err)
+		return nil, domainError(err)
+	}
+
+	return user, nil
+}
+
+// DeleteUser deactivates the user. Their account is hidden right away and
+// purged once the retention period has passed, unless they log in again.
+func (s *service) DeleteUser(ctx context.Context, id int) error {
+	if err := s.db.DeleteUser(ctx, id); err != nil {
+		log.Printf("error deleting user: %+v", err)
+		return domainError(err)
 	}
 
 	return nil
+}
+
+// IsActive reports whether the user exists and has not deleted their account.
+func (s *service) IsActive(ctx context.Context, id int) (bool, error) {
+	_, err := s.db.GetUser(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("error fetching user: %+v", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// PurgeDeletedUsers hard-deletes the accounts deleted longer ago than the
+// retention period, returning how many were removed.
+func (s *service) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	purged, err := s.db.PurgeDeletedUsers(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		log.Printf("error purging users: %+v", err)
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// parseDOB reads an optional date of birth, where "" means none was given.
+func parseDOB(dob string) (*model.Date, error) {
+	if dob == "" {
+		return nil, nil
+	}
+
+	date, err := model.ParseDate(dob)
+	if err != nil {
+		return nil, err
+	}
+
+	return &date, nil
+}
+
+// domainError translates repository errors into the errors of this package.
+func domainError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUserNotFound
+	}
+
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		switch conflict.Constraint {
+		case emailConstraint:
+			return ErrEmailTaken
+		case handleConstraint:
+			return ErrHandleTaken
+		}
+	}
+
+	return err
 }
\ No newline at end of file
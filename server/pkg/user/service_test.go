@@ -1,52 +1,85 @@
 package user
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 	"x/pkg/model"
+	"x/pkg/repository"
 	"x/pkg/util"
 
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type mockRepo struct {
 	mock.Mock
 }
 
-func (m *mockRepo) GetAllUsers() ([]model.User, error) {
-	args := m.Called()
+func (m *mockRepo) GetAllUsers(ctx context.Context, query model.UserQuery) ([]model.User, error) {
+	args := m.Called(query)
 
 	return args.Get(0).([]model.User), args.Error(1)
 }
 
-func (m *mockRepo) CreateUser(name, email, bio string, dob interface{}) error {
-	args := m.Called(name, email, bio, dob)
+func (m *mockRepo) CreateUser(ctx context.Context, name, handle, email, bio string, dob *model.Date, passwordHash string) error {
+	args := m.Called(name, handle, email, bio, dob, passwordHash)
 
 	return args.Error(0)
 }
 
-func (m *mockRepo) UpdateUser(id int, name, email, bio string, dob interface{}) error {
-	args := m.Called(id, name, email, bio, dob)
+func (m *mockRepo) UpdateUser(ctx context.Context, id int, name, handle, email, bio string, dob *model.Date, version time.Time) (*model.User, error) {
+	args := m.Called(id, name, handle, email, bio, dob, version)
 
-	return args.Error(0)
+	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (m *mockRepo) GetUser(id int) (*model.User, error) {
+func (m *mockRepo) GetUser(ctx context.Context, id int) (*model.User, error) {
 	args := m.Called(id)
 
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (m *mockRepo) GetUserByEmail(email string) (*model.User, error) {
-	args := m.Called(email)
+func (m *mockRepo) GetUserByHandle(ctx context.Context, handle string) (*model.User, error) {
+	args := m.Called(handle)
 
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *mockRepo) HandleExists(ctx context.Context, handle string) (bool, error) {
+	args := m.Called(handle)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockRepo) GetCredentials(ctx context.Context, email string) (*model.Credentials, error) {
+	args := m.Called(email)
+
+	return args.Get(0).(*model.Credentials), args.Error(1)
+}
+
+func (m *mockRepo) DeleteUser(ctx context.Context, id int) error {
+	args := m.Called(id)
+
+	return args.Error(0)
+}
+
+func (m *mockRepo) ReactivateUser(ctx context.Context, id int) error {
+	args := m.Called(id)
+
+	return args.Error(0)
+}
+
+func (m *mockRepo) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(deletedBefore)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestGetAllUsers_ReturnsUsers(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
 	dummyTime := time.Now()
 
@@ -67,23 +100,26 @@ func TestGetAllUsers_ReturnsUsers(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("GetAllUsers").Return(expected, nil)
+	mockRepo.On("GetAllUsers", model.UserQuery{SortBy: model.SortByName, Limit: model.DefaultPageLimit + 1}).Return(expected, nil)
 
-	actual, _ := service.GetAllUsers()
-	util.AssertJSON(actual, expected, t)
+	actual, _ := service.GetAllUsers(context.Background(), model.ListUsers{})
+	util.AssertJSON(actual.Items, expected, t)
+	if actual.NextCursor != "" {
+		t.Errorf("expected no next cursor, actual: %+v", actual.NextCursor)
+	}
 
 	mockRepo.AssertExpectations(t)
 }
 
 func TestGetAllUsersFails_ReturnsError(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
 	expected := errors.New("test error")
 
-	mockRepo.On("GetAllUsers").Return(([]model.User)(nil), expected)
+	mockRepo.On("GetAllUsers", mock.Anything).Return(([]model.User)(nil), expected)
 
-	_, actual := service.GetAllUsers()
+	_, actual := service.GetAllUsers(context.Background(), model.ListUsers{})
 	if actual != expected {
 		t.Errorf("expected %+v, actual: %+v", expected, actual)
 	}
@@ -91,37 +127,96 @@ func TestGetAllUsersFails_ReturnsError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetUserByEmail_ReturnsUser(t *testing.T) {
+func TestGetAllUsers_WithMorePages_ReturnsNextCursor(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	dummyTime := time.Now().UTC()
+
+	users := []model.User{
+		{ID: 3, Name: "user 3", UpsertedAt: dummyTime},
+		{ID: 2, Name: "user 2", UpsertedAt: dummyTime.Add(-time.Minute)},
+	}
+
+	mockRepo.On("GetAllUsers", model.UserQuery{NamePrefix: "us", SortBy: model.SortByUpsertedAt, Descending: true, Limit: 2}).Return(users, nil)
+
+	actual, err := service.GetAllUsers(context.Background(), model.ListUsers{NamePrefix: "us", Sort: "-upsertedAt", Limit: 1})
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual.Items, users[:1], t)
+
+	next, err := model.ParseUserCursor(actual.NextCursor, model.SortByUpsertedAt, true)
+	if err != nil {
+		t.Fatalf("expected a valid next cursor, actual: %+v", err)
+	}
+
+	if next.ID != 3 || !next.UpsertedAt.Equal(dummyTime) {
+		t.Errorf("expected cursor at user 3, actual: %+v", next)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllUsers_InvalidSort_ReturnsError(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
+
+	_, actual := service.GetAllUsers(context.Background(), model.ListUsers{Sort: "email"})
+	if !errors.Is(actual, model.ErrInvalidSort) {
+		t.Errorf("expected %+v, actual: %+v", model.ErrInvalidSort, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetAllUsers", mock.Anything)
+}
+
+func TestGetAllUsers_CursorForOtherSort_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	cursor := model.UserCursor{SortBy: model.SortByName, Name: "user 1", ID: 1}.String()
+
+	_, actual := service.GetAllUsers(context.Background(), model.ListUsers{Sort: "upsertedAt", Cursor: cursor})
+	if !errors.Is(actual, model.ErrInvalidCursor) {
+		t.Errorf("expected %+v, actual: %+v", model.ErrInvalidCursor, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetAllUsers", mock.Anything)
+}
+
+func TestGetUserByHandle_ReturnsUser(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
 
 	dummyTime := time.Now()
 
 	expected := &model.User{
 			ID: 1,
 			Name: "user 1",
+			Handle: "user1",
 			Email: "email1",
 			UpsertedAt: dummyTime,
 			Bio: "bio1",
 	}
 
-	mockRepo.On("GetUserByEmail", mock.AnythingOfType("string")).Return(expected, nil)
+	mockRepo.On("GetUserByHandle", mock.AnythingOfType("string")).Return(expected, nil)
 
-	actual, _ := service.GetUserByEmail("email1")
+	actual, _ := service.GetUserByHandle(context.Background(), "user1")
 	util.AssertJSON(actual, expected, t)
 
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetUserByEmailFails_ReturnsError(t *testing.T) {
+func TestGetUserByHandleFails_ReturnsError(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
 	expected := errors.New("test error")
 
-	mockRepo.On("GetUserByEmail", mock.AnythingOfType("string")).Return((*model.User)(nil), expected)
+	mockRepo.On("GetUserByHandle", mock.AnythingOfType("string")).Return((*model.User)(nil), expected)
 
-	_, actual := service.GetUserByEmail("email1")
+	_, actual := service.GetUserByHandle(context.Background(), "user1")
 	if actual != expected {
 		t.Errorf("expected %+v, actual: %+v", expected, actual)
 	}
@@ -129,19 +224,19 @@ func TestGetUserByEmailFails_ReturnsError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetUserByEmailNoUser_ReturnsNilUserAndError(t *testing.T) {
+func TestGetUserByHandleNoUser_ReturnsUserNotFound(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
-	mockRepo.On("GetUserByEmail", mock.AnythingOfType("string")).Return((*model.User)(nil), nil)
+	mockRepo.On("GetUserByHandle", mock.AnythingOfType("string")).Return((*model.User)(nil), repository.ErrNotFound)
 
-	actual, err := service.GetUserByEmail("email1")
+	actual, err := service.GetUserByHandle(context.Background(), "user1")
 	if actual != nil {
 		t.Errorf("expected %+v, actual: %+v", nil, actual)
 	}
 
-	if err != nil {
-		t.Errorf("expected %+v, actual: %+v", nil, err)
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected %+v, actual: %+v", ErrUserNotFound, err)
 	}
 
 	mockRepo.AssertExpectations(t)
@@ -149,11 +244,26 @@ func TestGetUserByEmailNoUser_ReturnsNilUserAndError(t *testing.T) {
 
 func TestCreateUser_ReturnsNoError(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
+
+	mockRepo.On("CreateUser", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("*model.Date"), mock.AnythingOfType("string")).Return(nil)
+
+	actual := service.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", "29-07-1997", "password1")
+	if actual != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateUser_WithISODOB_ParsesIt(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
 
-	mockRepo.On("CreateUser", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+	expected := &model.Date{Time: time.Date(1997, time.July, 29, 0, 0, 0, 0, time.UTC)}
+	mockRepo.On("CreateUser", "Varun Gupta", "varun", "email1", "bio1", expected, mock.AnythingOfType("string")).Return(nil)
 
-	actual := service.CreateUser("Varun Gupta", "email1", "bio1", "29-07-1997")
+	actual := service.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", "1997-07-29", "password1")
 	if actual != nil {
 		t.Errorf("expected: %+v, actual: %+v", nil, actual)
 	}
@@ -161,13 +271,25 @@ func TestCreateUser_ReturnsNoError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateUser_WithInvalidDOB_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	actual := service.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", "99-99-0", "password1")
+	if !errors.Is(actual, model.ErrInvalidDate) {
+		t.Errorf("expected: %+v, actual: %+v", model.ErrInvalidDate, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreateUser")
+}
+
 func TestCreateUser_WithEmptyDOB_ReturnsNoError(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
-	mockRepo.On("CreateUser", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything).Return(nil)
+	mockRepo.On("CreateUser", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("string")).Return(nil)
 
-	actual := service.CreateUser("Varun Gupta", "email1", "bio1", "")
+	actual := service.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", "", "password1")
 	if actual != nil {
 		t.Errorf("expected: %+v, actual: %+v", nil, actual)
 	}
@@ -175,15 +297,102 @@ func TestCreateUser_WithEmptyDOB_ReturnsNoError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateUser_HashesPassword(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	var passwordHash string
+	mockRepo.On("CreateUser", "Varun Gupta", "varun", "email1", "bio1", mock.Anything, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		passwordHash = args.String(5)
+	}).Return(nil)
+
+	if err := service.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", "", "password1"); err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	if passwordHash == "password1" {
+		t.Errorf("expected password to be hashed")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte("password1")); err != nil {
+		t.Errorf("expected hash to match password, actual: %+v", err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateUser_EmailTaken_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	conflict := &repository.ConflictError{Constraint: "users_email_key", Err: errors.New("duplicate key")}
+
+	mockRepo.On("CreateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(conflict)
+
+	actual := service.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", "", "password1")
+	if !errors.Is(actual, ErrEmailTaken) {
+		t.Errorf("expected %+v, actual: %+v", ErrEmailTaken, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateUser_HandleTaken_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	conflict := &repository.ConflictError{Constraint: "users_handle_key", Err: errors.New("duplicate key")}
+
+	mockRepo.On("CreateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(conflict)
+
+	actual := service.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", "", "password1")
+	if !errors.Is(actual, ErrHandleTaken) {
+		t.Errorf("expected %+v, actual: %+v", ErrHandleTaken, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCheckHandle(t *testing.T) {
+	cases := []struct {
+		handle string
+		taken bool
+		available bool
+	}{
+		{"varun", false, true},
+		{"varun", true, false},
+		{"Admin", false, false},
+		{"no spaces", false, false},
+		{"ab", false, false},
+		{"", false, false},
+	}
+
+	for _, c := range cases {
+		mockRepo := &mockRepo{}
+		service := New(mockRepo, DefaultRetention)
+
+		mockRepo.On("HandleExists", c.handle).Return(c.taken, nil)
+
+		actual, err := service.CheckHandle(context.Background(), c.handle)
+		if err != nil {
+			t.Fatalf("expected: %+v, actual: %+v", nil, err)
+		}
+
+		if actual.Available != c.available || (actual.Reason == "") != c.available {
+			t.Errorf("%q taken %v: expected available %v, actual: %+v", c.handle, c.taken, c.available, actual)
+		}
+	}
+}
+
 func TestCreateUser_ReturnsError(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
 	expected := errors.New("test error")
 
-	mockRepo.On("CreateUser", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(expected)
+	mockRepo.On("CreateUser", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("*model.Date"), mock.AnythingOfType("string")).Return(expected)
 
-	actual := service.CreateUser("Varun Gupta", "email1", "bio1", "29-07-1997")
+	actual := service.CreateUser(context.Background(), "Varun Gupta", "varun", "email1", "bio1", "29-07-1997", "password1")
 	if actual != expected {
 		t.Errorf("expected %+v, actual: %+v", expected, actual)
 	}
@@ -191,22 +400,30 @@ func TestCreateUser_ReturnsError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func TestUpdateUser_ReturnsNoError(t *testing.T) {
+func TestUpdateUser_AppliesPatch(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
-	dummyTime := time.Now()
-	mockRepo.On("GetUser", mock.AnythingOfType("int")).Return(&model.User{
+	version := time.Now()
+	mockRepo.On("GetUser", 1).Return(&model.User{
 		ID: 1,
 		Name: "Varun Gupta",
+		Handle: "varun",
+		Email: "email1",
 		Bio: "bio",
-		DOB: &dummyTime,
+		DOB: &model.Date{Time: version},
+		UpsertedAt: version,
 	}, nil)
-	mockRepo.On("UpdateUser", mock.AnythingOfType("int"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything).Return(nil)
 
-	actual := service.UpdateUser(1, "Varun Gupta", "email1", "bio1", "29-07-1997")
-	if actual != nil {
-		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	expected := &model.User{ID: 1, Name: "Varun Gupta", Handle: "varun", Email: "email1", Bio: "bio1"}
+	mockRepo.On("UpdateUser", 1, "Varun Gupta", "varun", "email1", "bio1", (*model.Date)(nil), version).Return(expected, nil)
+
+	actual, err := service.UpdateUser(context.Background(), 1, version, model.UpdateUser{
+		Bio: model.Some("bio1"),
+		DOB: model.Null[string](),
+	})
+	if err != nil || actual != expected {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", expected, actual, err)
 	}
 
 	mockRepo.AssertExpectations(t)
@@ -214,35 +431,71 @@ func TestUpdateUser_ReturnsNoError(t *testing.T) {
 
 func TestUpdateUser_FailsToGetUser_ReturnsError(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
 	expected := errors.New("test error")
 	mockRepo.On("GetUser", mock.AnythingOfType("int")).Return((*model.User)(nil), expected)
 
-	actual := service.UpdateUser(1, "Varun Gupta", "email1", "bio1", "29-07-1997")
+	_, actual := service.UpdateUser(context.Background(), 1, time.Now(), model.UpdateUser{Name: model.Some("Varun Gupta")})
 	if actual != expected {
-		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+		t.Errorf("expected: %+v, actual: %+v", expected, actual)
 	}
 
 	mockRepo.AssertExpectations(t)
 }
 
-func TestUpdateUser_WithEmptyData_ReturnsNoError(t *testing.T) {
+func TestUpdateUser_WithEmptyPatch_KeepsUser(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
-	dummyTime := time.Now()
-	mockRepo.On("GetUser", mock.AnythingOfType("int")).Return(&model.User{
+	version := time.Now()
+	dob := &model.Date{Time: version}
+	current := &model.User{
 		ID: 1,
 		Name: "Varun Gupta",
+		Handle: "varun",
+		Email: "email1",
 		Bio: "bio",
-		DOB: &dummyTime,
-	}, nil)
-	mockRepo.On("UpdateUser", mock.AnythingOfType("int"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything).Return(nil)
+		DOB: dob,
+		UpsertedAt: version,
+	}
+	mockRepo.On("GetUser", 1).Return(current, nil)
+	mockRepo.On("UpdateUser", 1, "Varun Gupta", "varun", "email1", "bio", dob, version).Return(current, nil)
 
-	actual := service.UpdateUser(1, "", "", nil, "")
-	if actual != nil {
-		t.Errorf("expected: %+v, actual: %+v", nil, actual)
+	_, err := service.UpdateUser(context.Background(), 1, version, model.UpdateUser{})
+	if err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateUser_StaleVersion_ReturnsVersionMismatch(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	version := time.Now()
+	mockRepo.On("GetUser", 1).Return(&model.User{ID: 1, UpsertedAt: version}, nil)
+
+	_, actual := service.UpdateUser(context.Background(), 1, version.Add(-time.Second), model.UpdateUser{Bio: model.Some("bio1")})
+	if actual != ErrVersionMismatch {
+		t.Errorf("expected: %+v, actual: %+v", ErrVersionMismatch, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "UpdateUser")
+}
+
+func TestUpdateUser_ConcurrentUpdate_ReturnsVersionMismatch(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	version := time.Now()
+	mockRepo.On("GetUser", 1).Return(&model.User{ID: 1, UpsertedAt: version}, nil)
+	mockRepo.On("UpdateUser", 1, "", "", "", "bio1", (*model.Date)(nil), version).Return((*model.User)(nil), repository.ErrNotFound)
+
+	_, actual := service.UpdateUser(context.Background(), 1, version, model.UpdateUser{Bio: model.Some("bio1")})
+	if actual != ErrVersionMismatch {
+		t.Errorf("expected: %+v, actual: %+v", ErrVersionMismatch, actual)
 	}
 
 	mockRepo.AssertExpectations(t)
@@ -250,23 +503,159 @@ func TestUpdateUser_WithEmptyData_ReturnsNoError(t *testing.T) {
 
 func TestUpdateUser_ReturnsError(t *testing.T) {
 	mockRepo := &mockRepo{}
-	service := New(mockRepo)
+	service := New(mockRepo, DefaultRetention)
 
 	expected := errors.New("test error")
 
-	dummyTime := time.Now()
+	version := time.Now()
 	mockRepo.On("GetUser", mock.AnythingOfType("int")).Return(&model.User{
 		ID: 1,
 		Name: "Varun Gupta",
 		Bio: "bio",
-		DOB: &dummyTime,
+		UpsertedAt: version,
 	}, nil)
-	mockRepo.On("UpdateUser", mock.AnythingOfType("int"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything).Return(expected)
+	mockRepo.On("UpdateUser", mock.AnythingOfType("int"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything, version).Return((*model.User)(nil), expected)
 
-	actual := service.UpdateUser(1, "Varun Gupta", "email1", "bio1", "29-07-1997")
+	_, actual := service.UpdateUser(context.Background(), 1, version, model.UpdateUser{DOB: model.Some("29-07-1997")})
 	if actual != expected {
 		t.Errorf("expected %+v, actual: %+v", expected, actual)
 	}
 
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestLogin_ReturnsUser(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	expected := &model.User{ID: 1, Name: "Varun Gupta", Email: "email1"}
+
+	mockRepo.On("GetCredentials", "email1").Return(&model.Credentials{ID: 1, PasswordHash: string(passwordHash)}, nil)
+	mockRepo.On("GetUser", 1).Return(expected, nil)
+
+	actual, err := service.Login(context.Background(), "email1", "password1")
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual, expected, t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_WrongPassword_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+
+	mockRepo.On("GetCredentials", "email1").Return(&model.Credentials{ID: 1, PasswordHash: string(passwordHash)}, nil)
+
+	_, actual := service.Login(context.Background(), "email1", "password2")
+	if !errors.Is(actual, ErrInvalidCredentials) {
+		t.Errorf("expected %+v, actual: %+v", ErrInvalidCredentials, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetUser", mock.Anything)
+}
+
+func TestLogin_UnknownEmail_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	mockRepo.On("GetCredentials", "email1").Return((*model.Credentials)(nil), repository.ErrNotFound)
+
+	_, actual := service.Login(context.Background(), "email1", "password1")
+	if !errors.Is(actual, ErrInvalidCredentials) {
+		t.Errorf("expected %+v, actual: %+v", ErrInvalidCredentials, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_DeletedWithinRetention_ReactivatesUser(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	deletedAt := time.Now().Add(-time.Hour)
+	expected := &model.User{ID: 1, Name: "Varun Gupta", Email: "email1"}
+
+	mockRepo.On("GetCredentials", "email1").Return(&model.Credentials{ID: 1, PasswordHash: string(passwordHash), DeletedAt: &deletedAt}, nil)
+	mockRepo.On("ReactivateUser", 1).Return(nil)
+	mockRepo.On("GetUser", 1).Return(expected, nil)
+
+	actual, err := service.Login(context.Background(), "email1", "password1")
+	if err != nil {
+		t.Fatalf("expected: %+v, actual: %+v", nil, err)
+	}
+
+	util.AssertJSON(actual, expected, t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_DeletedPastRetention_ReturnsError(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, time.Hour)
+
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	deletedAt := time.Now().Add(-2 * time.Hour)
+
+	mockRepo.On("GetCredentials", "email1").Return(&model.Credentials{ID: 1, PasswordHash: string(passwordHash), DeletedAt: &deletedAt}, nil)
+
+	_, actual := service.Login(context.Background(), "email1", "password1")
+	if !errors.Is(actual, ErrInvalidCredentials) {
+		t.Errorf("expected %+v, actual: %+v", ErrInvalidCredentials, actual)
+	}
+
+	mockRepo.AssertNotCalled(t, "ReactivateUser", mock.Anything)
+}
+
+func TestDeleteUser_NoUser_ReturnsUserNotFound(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	mockRepo.On("DeleteUser", 1).Return(repository.ErrNotFound)
+
+	actual := service.DeleteUser(context.Background(), 1)
+	if !errors.Is(actual, ErrUserNotFound) {
+		t.Errorf("expected %+v, actual: %+v", ErrUserNotFound, actual)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIsActive_DeletedUser_ReturnsFalse(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, DefaultRetention)
+
+	mockRepo.On("GetUser", 1).Return(&model.User{ID: 1}, nil)
+	mockRepo.On("GetUser", 2).Return((*model.User)(nil), repository.ErrNotFound)
+
+	if active, err := service.IsActive(context.Background(), 1); !active || err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", true, active, err)
+	}
+
+	if active, err := service.IsActive(context.Background(), 2); active || err != nil {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", false, active, err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeDeletedUsers_UsesRetention(t *testing.T) {
+	mockRepo := &mockRepo{}
+	service := New(mockRepo, time.Hour)
+
+	before := time.Now().Add(-time.Hour)
+	mockRepo.On("PurgeDeletedUsers", mock.MatchedBy(func(cutoff time.Time) bool {
+		return !cutoff.Before(before) && cutoff.Before(time.Now().Add(-59*time.Minute))
+	})).Return(int64(3), nil)
+
+	actual, err := service.PurgeDeletedUsers(context.Background())
+	if err != nil || actual != 3 {
+		t.Errorf("expected: %+v, actual: %+v, error: %+v", 3, actual, err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
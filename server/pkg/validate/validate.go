@@ -0,0 +1,145 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Errors lists the validation failures of a request by field name. A request
+// is valid when it has none.
+type Errors map[string][]string
+
+// Rule checks a single value and returns what is wrong with it, or "" when it
+// is fine. Rules other than Required accept the empty string, so that optional
+// fields are only checked when they are set.
+type Rule func(value string) string
+
+// Field runs every rule against value and records the failures under field.
+func (e Errors) Field(field, value string, rules ...Rule) {
+	for _, rule := range rules {
+		if message := rule(value); message != "" {
+			e.Add(field, message)
+		}
+	}
+}
+
+func (e Errors) Add(field, message string) {
+	e[field] = append(e[field], message)
+}
+
+// Err returns e as an error, or nil when there are no failures.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field+": "+strings.Join(e[field], ", "))
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func Required(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "is required"
+	}
+
+	return ""
+}
+
+func MinLength(n int) Rule {
+	return func(value string) string {
+		if value != "" && utf8.RuneCountInString(value) < n {
+			return fmt.Sprintf("should be at least %d characters", n)
+		}
+
+		return ""
+	}
+}
+
+func MaxLength(n int) Rule {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("should be at most %d characters", n)
+		}
+
+		return ""
+	}
+}
+
+// MaxBytes limits the encoded size of value rather than its characters, for
+// values such as passwords whose consumers count bytes.
+func MaxBytes(n int) Rule {
+	return func(value string) string {
+		if len(value) > n {
+			return fmt.Sprintf("should be at most %d bytes", n)
+		}
+
+		return ""
+	}
+}
+
+func Email(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return "should be a valid email address"
+	}
+
+	return ""
+}
+
+// ParseDate reads a date from a string, failing when it is not a valid date.
+type ParseDate func(value string) (time.Time, error)
+
+// Date checks value is a real calendar date as understood by parse, rejecting
+// inputs such as "31-02-2000".
+func Date(parse ParseDate) Rule {
+	return func(value string) string {
+		if value == "" {
+			return ""
+		}
+
+		if _, err := parse(value); err != nil {
+			return "should be a valid date"
+		}
+
+		return ""
+	}
+}
+
+// NotInFuture checks a date read by parse is not after today. Dates that do
+// not parse are left to Date.
+func NotInFuture(parse ParseDate) Rule {
+	return func(value string) string {
+		if value == "" {
+			return ""
+		}
+
+		date, err := parse(value)
+		if err == nil && date.After(time.Now()) {
+			return "should not be in the future"
+		}
+
+		return ""
+	}
+}
@@ -0,0 +1,109 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseDDMMYYYY(value string) (time.Time, error) {
+	return time.Parse("2-1-2006", value)
+}
+
+func TestField_CollectsEveryFailure(t *testing.T) {
+	errs := Errors{}
+
+	errs.Field("email", strings.Repeat("a", 11), Required, MaxLength(10), Email)
+
+	if len(errs["email"]) != 2 {
+		t.Errorf("expected: %+v, actual: %+v", 2, errs["email"])
+	}
+}
+
+func TestErr_NoFailures_ReturnsNil(t *testing.T) {
+	errs := Errors{}
+
+	errs.Field("name", "michael", Required, MaxLength(10))
+
+	if err := errs.Err(); err != nil {
+		t.Errorf("expected: %+v, actual: %+v", nil, err)
+	}
+}
+
+func TestRules_SkipEmptyValues(t *testing.T) {
+	for _, rule := range []Rule{MinLength(1), MaxLength(0), MaxBytes(0), Email, Date(parseDDMMYYYY), NotInFuture(parseDDMMYYYY)} {
+		if message := rule(""); message != "" {
+			t.Errorf("expected empty value to pass, actual: %+v", message)
+		}
+	}
+
+	if message := Required("  "); message == "" {
+		t.Errorf("expected blank value to fail Required")
+	}
+}
+
+func TestMinLength_CountsCharacters(t *testing.T) {
+	if message := MinLength(3)("äöü"); message != "" {
+		t.Errorf("expected three characters to pass, actual: %+v", message)
+	}
+
+	if message := MinLength(3)("ab"); message == "" {
+		t.Errorf("expected two characters to fail")
+	}
+}
+
+func TestMaxBytes_CountsBytes(t *testing.T) {
+	if message := MaxBytes(4)("äöü"); message == "" {
+		t.Errorf("expected six bytes to fail")
+	}
+
+	if message := MaxBytes(4)("abcd"); message != "" {
+		t.Errorf("expected four bytes to pass, actual: %+v", message)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	cases := map[string]bool{
+		"michael@dundermifflin.com": true,
+		"michael": false,
+		"Michael <michael@dundermifflin.com>": false,
+		"@dundermifflin.com": false,
+	}
+
+	for email, valid := range cases {
+		if actual := Email(email) == ""; actual != valid {
+			t.Errorf("%q: expected: %+v, actual: %+v", email, valid, actual)
+		}
+	}
+}
+
+func TestDate_RejectsImpossibleDates(t *testing.T) {
+	cases := map[string]bool{
+		"15-03-1965": true,
+		"1-5-2000": true,
+		"29-02-2000": true,
+		"29-02-2001": false,
+		"99-99-0": false,
+		"-1-5-2000": false,
+	}
+
+	rule := Date(parseDDMMYYYY)
+	for date, valid := range cases {
+		if actual := rule(date) == ""; actual != valid {
+			t.Errorf("%q: expected: %+v, actual: %+v", date, valid, actual)
+		}
+	}
+}
+
+func TestNotInFuture(t *testing.T) {
+	rule := NotInFuture(parseDDMMYYYY)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2-1-2006")
+
+	if message := rule(tomorrow); message == "" {
+		t.Errorf("expected %q to fail", tomorrow)
+	}
+
+	if message := rule("15-03-1965"); message != "" {
+		t.Errorf("expected: %+v, actual: %+v", "", message)
+	}
+}